@@ -89,10 +89,11 @@ func Doc() {
 			qdoc.RequiredParam("origin", doc.Schema("mobile-app")), // example value will be "mobile-app"
 		),
 		RespSet: qdoc.RespSet{
-			Success: qdoc.ResJson("Team creation success", nil),
-			BadReq:  qdoc.ResJson("Invalid team data", nil),
-			ISE:     qdoc.ResJson("Internal server error", nil),
-		},
+			BadReq: qdoc.ResJson("Invalid team data", nil),
+			ISE:    qdoc.ResJson("Internal server error", nil),
+		}.With(qdoc.HTTP_CREATED, qdoc.ResJson("Team creation success", nil).WithHeaders(
+			qdoc.RequiredParam("Location", qdoc.SchemaOf[string](doc)), // url of the new team
+		)),
 	}).Tag("Team").WithBearerAuth() // Add bearer token authentication requirement
 
 	// Get request with complex schema
@@ -114,10 +115,10 @@ func Doc() {
 		Desc:    "Get a Option Endpoint",
 		Path:    "/v1.0/sku/option/{option}",
 		PathParams: qdoc.PathParams(
-			qdoc.RequiredParam("option", doc.Schema(nil)),
+			qdoc.RequiredParam("option", qdoc.SchemaOf[string](doc)),
 		),
 		Headers: qdoc.Headers(
-			qdoc.OptionalParam("type", doc.Schema(nil)),
+			qdoc.OptionalParam("type", qdoc.SchemaOf[string](doc)),
 		),
 		RespSet: qdoc.RespSet{
 			Success: qdoc.ResJson("Success", doc.Schema(OptionGetResponse{
@@ -175,11 +176,11 @@ func Doc() {
 
 type test struct {
 	//name string `json:"name"`
-	names []test2 `json:"names"`
+	Names []test2 `json:"names"`
 }
 
 type test2 struct {
-	name2 string `json:"name_2"`
+	Name2 string `json:"name_2"`
 }
 
 type OptionGetResponse struct {
@@ -2,18 +2,35 @@ package qdoc
 
 import (
 	"context"
+	"fmt"
 	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/pickme-lk/quick-doc/ui"
+	"sort"
 )
 
 type CompiledDoc struct {
-	Json   []byte
-	config Config
-	specs  *openapi3.T
+	Json []byte
+	// Skipped field paths which were left out by schema.KindPolicy_SKIP
+	Skipped []string
+	config  Config
+	specs   *openapi3.T
+	uiHTML  map[ui.Theme]string
 }
 
 func (d *Doc) Compile() (*CompiledDoc, error) {
-	spec := d.compileSpecs(d)
-	err := spec.Validate(context.Background())
+	// options may have been changed since the last compile
+	if d.schemaOpts.Cache != nil {
+		d.schemaOpts.Cache.Reset()
+	}
+	for _, sc := range d.schemas {
+		sc.props = nil
+		sc.skipped = nil
+	}
+	spec, err := d.compileSpecs(d)
+	if err != nil {
+		return nil, err
+	}
+	err = spec.Validate(context.Background())
 	if err != nil {
 		return nil, err
 	}
@@ -21,14 +38,80 @@ func (d *Doc) Compile() (*CompiledDoc, error) {
 	if err != nil {
 		return nil, err
 	}
+	uiHTML, err := d.compileUi()
+	if err != nil {
+		return nil, err
+	}
 	return &CompiledDoc{
-		config: d.config,
-		specs:  spec,
-		Json:   bytes,
+		config:  d.config,
+		specs:   spec,
+		Json:    bytes,
+		Skipped: d.skippedFields(),
+		uiHTML:  uiHTML,
 	}, nil
 }
 
-func (d *Doc) compileSpecs(doc *Doc) *openapi3.T {
+// skippedFields collects the skipped field paths of the compiled schemas
+func (d *Doc) skippedFields() []string {
+	var skipped []string
+	seen := make(map[string]bool)
+	for _, sc := range d.schemas {
+		for _, path := range sc.skipped {
+			if !seen[path] {
+				seen[path] = true
+				skipped = append(skipped, path)
+			}
+		}
+	}
+	sort.Strings(skipped)
+	return skipped
+}
+
+// compileUi generates the web viewer HTML of the default theme, or of every
+// theme when the theme is selected by query
+func (d *Doc) compileUi() (map[ui.Theme]string, error) {
+	htmlMap := make(map[ui.Theme]string)
+	if !d.config.UiConfig.Enabled {
+		return htmlMap, nil
+	}
+
+	themes := []ui.Theme{d.config.UiConfig.DefaultTheme}
+	if d.config.UiConfig.ThemeByQuery {
+		themes = []ui.Theme{ui.SWAGGER_UI, ui.RAPI_DOC}
+	}
+	for _, theme := range themes {
+		html, err := ui.HTML(ui.Config{
+			Theme:   theme,
+			Title:   d.config.Title,
+			SpecUrl: d.config.SpecPath,
+			LogoUrl: d.config.UiConfig.LogoUrl,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("ui: %w", err)
+		}
+		htmlMap[theme] = html
+	}
+
+	if _, ok := htmlMap[d.config.UiConfig.DefaultTheme]; !ok {
+		return nil, fmt.Errorf("ui: default theme %s not found", d.config.UiConfig.DefaultTheme)
+	}
+	return htmlMap, nil
+}
+
+func (d *Doc) compileSpecs(doc *Doc) (*openapi3.T, error) {
+	comps := newComponents(d.nameTag())
+	// components are compiled first, so their examples define the component schemas
+	if err := d.compileComponents(comps); err != nil {
+		return nil, err
+	}
+	paths, err := d.compilePaths(comps)
+	if err != nil {
+		return nil, err
+	}
+	schemas, err := comps.compile()
+	if err != nil {
+		return nil, err
+	}
 	spec := openapi3.T{
 		OpenAPI: "3.0.3",
 		Info: &openapi3.Info{
@@ -37,12 +120,14 @@ func (d *Doc) compileSpecs(doc *Doc) *openapi3.T {
 			Version:     doc.config.Version,
 		},
 		Servers: d.compileServerList(),
-		Paths:   d.compilePaths(),
+		Paths:   paths,
 		Components: openapi3.Components{
 			SecuritySchemes: d.compileSecuritySchemes(),
+			Schemas:         schemas,
+			Examples:        comps.compileExamples(),
 		},
 	}
-	return &spec
+	return &spec, nil
 }
 
 func (d *Doc) compileServerList() []*openapi3.Server {
@@ -84,10 +169,24 @@ func (d *Doc) compileSecuritySchemes() openapi3.SecuritySchemes {
 	return securitySchemes
 }
 
-func (d *Doc) compilePaths() openapi3.Paths {
+func (d *Doc) compileComponents(c *components) error {
+	for _, sc := range d.schemas {
+		if sc.component {
+			if _, err := sc.toOpenAPI(c); err != nil {
+				return fmt.Errorf("component: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+func (d *Doc) compilePaths(c *components) (openapi3.Paths, error) {
 	paths := make(openapi3.Paths)
 	for _, e := range d.endpoints {
-		path, method, item := d.compileOperation(e)
+		path, method, item, err := d.compileOperation(c, e)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", e.method, e.Path, err)
+		}
 		pi := paths[path]
 		if pi == nil {
 			pi = &openapi3.PathItem{}
@@ -103,18 +202,31 @@ func (d *Doc) compilePaths() openapi3.Paths {
 			pi.Delete = &item
 		}
 	}
-	return paths
+	return paths, nil
 }
 
-func (d *Doc) compileOperation(ep *Endpoint) (path string, method MethodType, item openapi3.Operation) {
+func (d *Doc) compileOperation(c *components, ep *Endpoint) (path string, method MethodType, item openapi3.Operation, err error) {
 	path = ep.Path
+	method = ep.method
+	reqBody, err := ep.ReqBody.toOpenAPI(c)
+	if err != nil {
+		return
+	}
+	responses, err := ep.RespSet.toOpenAPI(c)
+	if err != nil {
+		return
+	}
+	params, err := d.compileParams(c, ep.PathParams, ep.QueryParams, ep.Headers)
+	if err != nil {
+		return
+	}
 	item = openapi3.Operation{
 		Summary:     ep.Summary,
 		Description: ep.Desc,
-		RequestBody: &openapi3.RequestBodyRef{Value: ep.ReqBody.toOpenAPI()},
-		Responses:   ep.RespSet.toOpenAPI(),
+		RequestBody: &openapi3.RequestBodyRef{Value: reqBody},
+		Responses:   responses,
 		Tags:        ep.tags,
-		Parameters:  d.compileParams(ep.PathParams, ep.QueryParams, ep.Headers),
+		Parameters:  params,
 	}
 	if ep.auth {
 		item.Security = openapi3.NewSecurityRequirements()
@@ -122,19 +234,22 @@ func (d *Doc) compileOperation(ep *Endpoint) (path string, method MethodType, it
 			item.Security.With(authType.toOpenAPISecurityRequirement())
 		}
 	}
-	method = ep.method
 	return
 }
 
 // compileParams converts the parameters into a list of openapi3.Parameters
-func (d *Doc) compileParams(paramSet ...Parameters) openapi3.Parameters {
+func (d *Doc) compileParams(c *components, paramSet ...Parameters) (openapi3.Parameters, error) {
 	_params := make(openapi3.Parameters, 0)
 	for _, params := range paramSet {
 		for _, p := range params {
+			param, err := p.toOpenAPI(c)
+			if err != nil {
+				return nil, err
+			}
 			_params = append(_params, &openapi3.ParameterRef{
-				Value: p.toOpenAPI(),
+				Value: param,
 			})
 		}
 	}
-	return _params
+	return _params, nil
 }
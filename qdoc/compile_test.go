@@ -0,0 +1,953 @@
+package qdoc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/pickme-lk/quick-doc/schema"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type Category struct {
+	Name     string      `json:"name"`
+	Children []*Category `json:"children"`
+}
+
+func newTestDoc() *Doc {
+	return NewDoc(Config{
+		Title:   "Test Doc",
+		Version: "1.0.0",
+	})
+}
+
+func Test_CompileRecursiveType(t *testing.T) {
+	doc := newTestDoc()
+	doc.Get(&Endpoint{
+		Path: "/category",
+		RespSet: RespSet{
+			Success: ResJson("Category tree", doc.Schema(Category{})),
+		},
+	})
+
+	cd, err := doc.Compile()
+	if err != nil {
+		t.Fatalf("error while compiling doc, %v", err)
+	}
+
+	component := cd.specs.Components.Schemas["Category"]
+	if component == nil {
+		t.Fatalf("component not found, got=%v", cd.specs.Components.Schemas)
+	}
+
+	children := component.Value.Properties["children"]
+	if children == nil || children.Value.Items.Ref != "#/components/schemas/Category" {
+		t.Errorf("children should refer to the component, got=%v", children)
+	}
+
+	resp := cd.specs.Paths["/category"].Get.Responses.Get(200).Value
+	if ref := resp.Content.Get(string(CONTENT_TYPE_JSON)).Schema.Ref; ref != "#/components/schemas/Category" {
+		t.Errorf("response should refer to the component, got=%s", ref)
+	}
+}
+
+type User struct {
+	Username string `json:"username"`
+	Age      int    `json:"age"`
+}
+
+func Test_CompileComponent(t *testing.T) {
+	doc := newTestDoc()
+	doc.Component(User{Username: "testuser1", Age: 24})
+	doc.Post(&Endpoint{
+		Path:    "/user",
+		ReqBody: ReqJson(doc.Schema(User{})),
+		RespSet: RespSet{
+			Success: ResJson("Users", doc.Schema([]User{})),
+		},
+	})
+
+	cd, err := doc.Compile()
+	if err != nil {
+		t.Fatalf("error while compiling doc, %v", err)
+	}
+
+	component := cd.specs.Components.Schemas["User"]
+	if component == nil {
+		t.Fatalf("component not found, got=%v", cd.specs.Components.Schemas)
+	}
+	if example := component.Value.Properties["username"].Value.Example; example != "testuser1" {
+		t.Errorf("component example should be taken from the component, got=%v", example)
+	}
+
+	op := cd.specs.Paths["/user"].Post
+	if ref := op.RequestBody.Value.Content.Get(string(CONTENT_TYPE_JSON)).Schema.Ref; ref != "#/components/schemas/User" {
+		t.Errorf("request body should refer to the component, got=%s", ref)
+	}
+	resp := op.Responses.Get(200).Value.Content.Get(string(CONTENT_TYPE_JSON)).Schema.Value
+	if ref := resp.Items.Ref; ref != "#/components/schemas/User" {
+		t.Errorf("response items should refer to the component, got=%s", ref)
+	}
+}
+
+func Test_ComponentNames(t *testing.T) {
+	c := newComponents(schema.DefaultNameTag)
+	for _, ref := range []string{
+		"main.Team",
+		"example.com/v1/models.User",
+		"example.com/v2/models.User",
+		"example.com/dto.User",
+		"main.Page[main.Team]",
+	} {
+		c.register(ref, nil)
+	}
+
+	want := map[string]string{
+		"main.Team":                  "Team",
+		"example.com/v1/models.User": "example.com.v1.models.User",
+		"example.com/v2/models.User": "example.com.v2.models.User",
+		"example.com/dto.User":       "dto.User",
+		"main.Page[main.Team]":       "Page_main.Team_",
+	}
+
+	got := c.names()
+	for ref, name := range want {
+		if got[ref] != name {
+			t.Errorf("not match ref=%s got=%s want=%s", ref, got[ref], name)
+		}
+	}
+}
+
+type SignUp struct {
+	Username string   `json:"username" validate:"required,min=3,max=20"`
+	Age      int      `json:"age" validate:"gte=18,lt=130"`
+	Tags     []string `json:"tags,omitempty" validate:"max=5"`
+	Nickname string   `json:"nickname,omitempty" validate:"gt=3,lt=20"`
+	Hobbies  []string `json:"hobbies,omitempty" validate:"gt=0"`
+}
+
+func Test_CompileConstraints(t *testing.T) {
+	doc := newTestDoc()
+	doc.Post(&Endpoint{
+		Path:    "/signup",
+		ReqBody: ReqJson(doc.Schema(SignUp{})),
+		RespSet: RespSet{
+			Success: ResJson("Signed up", nil),
+		},
+	})
+
+	cd, err := doc.Compile()
+	if err != nil {
+		t.Fatalf("error while compiling doc, %v", err)
+	}
+
+	body := cd.specs.Paths["/signup"].Post.RequestBody.Value.Content.Get(string(CONTENT_TYPE_JSON)).Schema.Value
+	if !reflect.DeepEqual(body.Required, []string{"username", "age"}) {
+		t.Errorf("required not match, got=%v", body.Required)
+	}
+
+	username := body.Properties["username"].Value
+	if username.MinLength != 3 || username.MaxLength == nil || *username.MaxLength != 20 {
+		t.Errorf("username length not match, got=%d,%v", username.MinLength, username.MaxLength)
+	}
+
+	age := body.Properties["age"].Value
+	if age.Min == nil || *age.Min != 18 || age.ExclusiveMin || age.Max == nil || *age.Max != 130 || !age.ExclusiveMax {
+		t.Errorf("age range not match, got=%v,%v", age.Min, age.Max)
+	}
+
+	tags := body.Properties["tags"].Value
+	if tags.MaxItems == nil || *tags.MaxItems != 5 {
+		t.Errorf("tags items not match, got=%v", tags.MaxItems)
+	}
+
+	nickname := body.Properties["nickname"].Value
+	if nickname.MinLength != 4 || nickname.MaxLength == nil || *nickname.MaxLength != 19 {
+		t.Errorf("exclusive nickname length not match, got=%d,%v", nickname.MinLength, nickname.MaxLength)
+	}
+
+	if hobbies := body.Properties["hobbies"].Value; hobbies.MinItems != 1 {
+		t.Errorf("exclusive hobbies items not match, got=%d", hobbies.MinItems)
+	}
+}
+
+type Admin struct {
+	User
+	Level int `json:"level"`
+}
+
+func Test_CompileEmbeddedAllOf(t *testing.T) {
+	doc := newTestDoc()
+	doc.SchemaOptions().EmbedAsAllOf = true
+	doc.Get(&Endpoint{
+		Path: "/admin",
+		RespSet: RespSet{
+			Success: ResJson("Admin", doc.Schema(Admin{})),
+		},
+	})
+
+	cd, err := doc.Compile()
+	if err != nil {
+		t.Fatalf("error while compiling doc, %v", err)
+	}
+
+	if cd.specs.Components.Schemas["User"] == nil {
+		t.Fatalf("component not found, got=%v", cd.specs.Components.Schemas)
+	}
+
+	resp := cd.specs.Paths["/admin"].Get.Responses.Get(200).Value.Content.Get(string(CONTENT_TYPE_JSON)).Schema.Value
+	if len(resp.AllOf) != 2 || resp.AllOf[0].Ref != "#/components/schemas/User" {
+		t.Fatalf("allOf not match, got=%v", resp.AllOf)
+	}
+	if resp.AllOf[1].Value.Properties["level"] == nil {
+		t.Errorf("own properties not found, got=%v", resp.AllOf[1].Value.Properties)
+	}
+}
+
+func Test_CompileTypedExamples(t *testing.T) {
+	doc := newTestDoc()
+	doc.Get(&Endpoint{
+		Path: "/user",
+		RespSet: RespSet{
+			Success: ResJson("User", doc.Schema([]User{{Username: "testuser1", Age: 24}})),
+		},
+	})
+
+	cd, err := doc.Compile()
+	if err != nil {
+		t.Fatalf("error while compiling doc, %v", err)
+	}
+
+	resp := cd.specs.Paths["/user"].Get.Responses.Get(200).Value.Content.Get(string(CONTENT_TYPE_JSON)).Schema.Value
+	example, err := json.Marshal(resp.Example)
+	if err != nil {
+		t.Fatalf("error while marshaling example, %v", err)
+	}
+	if want := `[{"age":24,"username":"testuser1"}]`; string(example) != want {
+		t.Errorf("example not match, got=%s want=%s", example, want)
+	}
+
+	age, err := json.Marshal(resp.Items.Value.Properties["age"].Value.Example)
+	if err != nil {
+		t.Fatalf("error while marshaling example, %v", err)
+	}
+	if string(age) != "24" {
+		t.Errorf("age example not match, got=%s", age)
+	}
+}
+
+type EventStream struct {
+	Events chan int `json:"events"`
+}
+
+func Test_CompileError(t *testing.T) {
+	doc := newTestDoc()
+	doc.SchemaOptions().KindPolicies = map[reflect.Kind]schema.KindPolicy{
+		reflect.Chan: schema.KindPolicy_ERROR,
+	}
+	doc.Get(&Endpoint{
+		Path: "/events",
+		RespSet: RespSet{
+			Success: ResJson("Events", doc.Schema(EventStream{})),
+		},
+	})
+
+	_, err := doc.Compile()
+
+	var typeErr *schema.UnsupportedTypeError
+	if !errors.As(err, &typeErr) {
+		t.Fatalf("unsupported type error expected, got=%v", err)
+	}
+	if want := "GET /events: response 200: schema: unsupported type chan int (chan) at EventStream.events"; err.Error() != want {
+		t.Errorf("error not match\ngot =%s\nwant=%s", err, want)
+	}
+}
+
+func Test_CompileSkipped(t *testing.T) {
+	doc := newTestDoc()
+	doc.Get(&Endpoint{
+		Path: "/events",
+		RespSet: RespSet{
+			Success: ResJson("Events", doc.Schema(EventStream{})),
+		},
+	})
+
+	cd, err := doc.Compile()
+	if err != nil {
+		t.Fatalf("error while compiling doc, %v", err)
+	}
+	if want := []string{"EventStream.events (chan int)"}; !reflect.DeepEqual(cd.Skipped, want) {
+		t.Errorf("skipped not match, got=%v want=%v", cd.Skipped, want)
+	}
+}
+
+type Invoice struct {
+	Number string `json:"number" form:"invoice_no" xml:"no,attr"`
+	Total  int    `json:"total" form:"total" xml:"total"`
+}
+
+func Test_CompileContentTypeNames(t *testing.T) {
+	doc := newTestDoc()
+	invoice := doc.Component(Invoice{Number: "INV-1", Total: 100})
+	doc.Post(&Endpoint{
+		Path:    "/invoices",
+		ReqBody: ReqBody(invoice)(CONTENT_TYPE_JSON, CONTENT_TYPE_FORM, CONTENT_TYPE_XML, CONTENT_TYPE_YAML),
+		RespSet: RespSet{
+			Success: ResJson("Invoice", invoice),
+		},
+	})
+
+	cd, err := doc.Compile()
+	if err != nil {
+		t.Fatalf("error while compiling doc, %v", err)
+	}
+
+	content := cd.specs.Paths["/invoices"].Post.RequestBody.Value.Content
+	refs := map[ContentType]string{
+		CONTENT_TYPE_JSON: "#/components/schemas/Invoice",
+		CONTENT_TYPE_FORM: "#/components/schemas/Invoice_form",
+		CONTENT_TYPE_XML:  "#/components/schemas/Invoice_xml",
+		CONTENT_TYPE_YAML: "#/components/schemas/Invoice_yaml",
+	}
+	for ct, want := range refs {
+		if got := content.Get(string(ct)).Schema.Ref; got != want {
+			t.Errorf("%s reference not match, got=%s want=%s", ct, got, want)
+		}
+	}
+
+	schemas := cd.specs.Components.Schemas
+	if form := schemas["Invoice_form"]; form == nil || form.Value.Properties["invoice_no"] == nil {
+		t.Errorf("form schema should be named by the form tag, got=%v", form)
+	}
+	if yaml := schemas["Invoice_yaml"]; yaml == nil || yaml.Value.Properties["number"] == nil {
+		t.Errorf("untagged yaml fields should be lowercased, got=%v", yaml)
+	}
+	xmlNo := schemas["Invoice_xml"].Value.Properties["no"]
+	if xmlNo == nil || xmlNo.Value.XML == nil || !xmlNo.Value.XML.Attribute {
+		t.Errorf("xml attribute not found, got=%v", xmlNo)
+	}
+}
+
+func Test_ContentTypeNameTag(t *testing.T) {
+	tests := map[ContentType]string{
+		CONTENT_TYPE_JSON:                            "json",
+		"application/vnd.api+json":                   "json",
+		"application/x-www-form-urlencoded; charset": "form",
+		CONTENT_TYPE_MULTIPART:                       "form",
+		"text/xml":                                   "xml",
+		CONTENT_TYPE_YAML:                            "yaml",
+		CONTENT_TYPE_MSGPACK:                         "msgpack",
+		CONTENT_TYPE_HTML:                            "",
+	}
+	for ct, want := range tests {
+		if got := ct.NameTag(); got != want {
+			t.Errorf("%s name tag not match, got=%s want=%s", ct, got, want)
+		}
+	}
+}
+
+type CardPayment struct {
+	Type string `json:"type"`
+	Card string `json:"card"`
+}
+
+type WalletPayment struct {
+	Type   string `json:"type"`
+	Wallet string `json:"wallet"`
+}
+
+func Test_CompileOneOf(t *testing.T) {
+	doc := newTestDoc()
+	doc.Get(&Endpoint{
+		Path: "/payments",
+		RespSet: RespSet{
+			Success: ResJson("Payment", doc.OneOf(CardPayment{}, WalletPayment{}).
+				Discriminator("type", map[string]interface{}{
+					"card":   CardPayment{},
+					"wallet": WalletPayment{},
+				})),
+		},
+	})
+
+	cd, err := doc.Compile()
+	if err != nil {
+		t.Fatalf("error while compiling doc, %v", err)
+	}
+
+	sc := cd.specs.Paths["/payments"].Get.Responses.Get(200).Value.Content.Get(string(CONTENT_TYPE_JSON)).Schema.Value
+	if len(sc.OneOf) != 2 || sc.OneOf[0].Ref != "#/components/schemas/CardPayment" || sc.OneOf[1].Ref != "#/components/schemas/WalletPayment" {
+		t.Errorf("one of not match, got=%v", sc.OneOf)
+	}
+	want := map[string]string{
+		"card":   "#/components/schemas/CardPayment",
+		"wallet": "#/components/schemas/WalletPayment",
+	}
+	if sc.Discriminator == nil || sc.Discriminator.PropertyName != "type" || !reflect.DeepEqual(sc.Discriminator.Mapping, want) {
+		t.Errorf("discriminator not match, got=%v", sc.Discriminator)
+	}
+
+	doc = newTestDoc()
+	doc.Get(&Endpoint{
+		Path: "/payments",
+		RespSet: RespSet{
+			Success: ResJson("Payment", doc.OneOf(CardPayment{}).
+				Discriminator("type", map[string]interface{}{"other": Employee{}})),
+		},
+	})
+	if _, err := doc.Compile(); err == nil || !strings.Contains(err.Error(), "Employee") {
+		t.Errorf("mapping to a type which is not a member should fail, got=%v", err)
+	}
+}
+
+type Employee struct {
+	Name    string    `json:"name"`
+	Nick    *string   `json:"nick"`
+	Manager *Employee `json:"manager"`
+}
+
+func Test_CompileNullable(t *testing.T) {
+	doc := newTestDoc()
+	doc.Get(&Endpoint{
+		Path: "/employees",
+		RespSet: RespSet{
+			Success: ResJson("Employee", doc.Schema(Employee{Name: "John"})),
+		},
+	})
+
+	cd, err := doc.Compile()
+	if err != nil {
+		t.Fatalf("error while compiling doc, %v", err)
+	}
+
+	component := cd.specs.Components.Schemas["Employee"].Value
+	if nick := component.Properties["nick"].Value; !nick.Nullable {
+		t.Errorf("nick should be nullable, got=%v", nick)
+	}
+	manager := component.Properties["manager"].Value
+	if !manager.Nullable || len(manager.AllOf) != 1 || manager.AllOf[0].Ref != "#/components/schemas/Employee" {
+		t.Errorf("manager should be a nullable reference, got=%v", manager)
+	}
+	if component.Nullable {
+		t.Errorf("root schema should not be nullable")
+	}
+}
+
+func Test_CompileSchemaOf(t *testing.T) {
+	doc := newTestDoc()
+	doc.Get(&Endpoint{
+		Path:       "/employees/{id}",
+		PathParams: PathParams(RequiredParam("id", SchemaOf[int](doc))),
+		RespSet: RespSet{
+			Success: ResJson("Employee", ComponentOf[Employee](doc)),
+		},
+	})
+
+	cd, err := doc.Compile()
+	if err != nil {
+		t.Fatalf("error while compiling doc, %v", err)
+	}
+
+	op := cd.specs.Paths["/employees/{id}"].Get
+	id := op.Parameters[0].Value.Schema.Value
+	if id.Type != "integer" || id.Example != int64(0) {
+		t.Errorf("id should be an integer with an example, got=%v", id)
+	}
+	component, ok := cd.specs.Components.Schemas["Employee"]
+	if !ok {
+		t.Fatalf("Employee should be a component")
+	}
+	if name := component.Value.Properties["name"].Value; name.Example != "string" {
+		t.Errorf("name should have a synthesized example, got=%v", name.Example)
+	}
+}
+
+func Test_CompileResponseStatus(t *testing.T) {
+	doc := newTestDoc()
+	doc.Post(&Endpoint{
+		Path: "/employees",
+		RespSet: RespSet{
+			BadReq: ResJson("Invalid employee", nil),
+		}.With(HTTP_CREATED, ResJson("Created", doc.Schema(Employee{Name: "John"})).WithHeaders(
+			RequiredParam("Location", SchemaOf[string](doc)).WithExample("employee", "/employees/1"),
+			OptionalParam("X-RateLimit-Remaining", doc.Schema(99)),
+		)).With(HTTP_4XX, ResJson("Client error", nil)).
+			With(HTTP_DEFAULT, ResJson("Unexpected error", nil)),
+	})
+
+	cd, err := doc.Compile()
+	if err != nil {
+		t.Fatalf("error while compiling doc, %v", err)
+	}
+
+	responses := cd.specs.Paths["/employees"].Post.Responses
+	for _, key := range []string{"201", "400", "4XX", "default"} {
+		if responses[key] == nil {
+			t.Errorf("response %s should be documented", key)
+		}
+	}
+	if len(responses) != 4 {
+		t.Errorf("responses not match, got=%d responses", len(responses))
+	}
+	headers := responses["201"].Value.Headers
+	location := headers["Location"]
+	if location == nil || !location.Value.Required || location.Value.Schema.Value.Type != "string" {
+		t.Errorf("location header not match, got=%v", location)
+	}
+	if location == nil || location.Value.Examples["employee"] == nil || location.Value.Examples["employee"].Value.Value != "/employees/1" {
+		t.Errorf("location header example not match, got=%v", location)
+	}
+	if remaining := headers["X-RateLimit-Remaining"]; remaining == nil || remaining.Value.Required {
+		t.Errorf("rate limit header should be optional, got=%v", remaining)
+	}
+}
+
+func Test_CompileResponseInvalidStatus(t *testing.T) {
+	doc := newTestDoc()
+	doc.Post(&Endpoint{
+		Path:    "/employees",
+		RespSet: RespSet{}.With(HttpStatus(0), ResJson("Invalid status", nil)),
+	})
+
+	_, err := doc.Compile()
+	if err == nil || !strings.Contains(err.Error(), "response 0: invalid status") {
+		t.Errorf("invalid status should fail the compile, got=%v", err)
+	}
+}
+
+func Test_CompileResponseBodies(t *testing.T) {
+	doc := newTestDoc()
+	doc.Get(&Endpoint{
+		Path: "/reports",
+		RespSet: RespSet{
+			Success:  ResFile("Report", ContentType("application/pdf"), ContentType("text/csv")),
+			NotFound: ResText("Report not found"),
+			ISE:      &Response{Description: "Internal server error"},
+		}.With(HTTP_NO_CONTENT, ResEmpty("Report is empty")).
+			With(HTTP_ACCEPTED, ResStream("Report progress")).
+			With(HttpStatus(203), ResHTML("Report page")),
+	})
+
+	cd, err := doc.Compile()
+	if err != nil {
+		t.Fatalf("error while compiling doc, %v", err)
+	}
+
+	responses := cd.specs.Paths["/reports"].Get.Responses
+	if len(responses) != 6 {
+		t.Fatalf("descriptive only responses should be kept, got=%d responses", len(responses))
+	}
+	for _, key := range []string{"204", "500"} {
+		if content := responses[key].Value.Content; content != nil {
+			t.Errorf("response %s should have no content, got=%v", key, content)
+		}
+	}
+	for _, ct := range []string{"application/pdf", "text/csv"} {
+		file := responses["200"].Value.Content[ct]
+		if file == nil || file.Schema.Value.Type != "string" || file.Schema.Value.Format != "binary" {
+			t.Errorf("%s response should be binary, got=%v", ct, file)
+		}
+	}
+	bodies := map[string]string{"404": "text/plain", "202": "text/event-stream", "203": "text/html"}
+	for key, ct := range bodies {
+		body := responses[key].Value.Content[ct]
+		if body == nil || body.Schema.Value.Type != "string" {
+			t.Errorf("response %s should have a %s string body, got=%v", key, ct, responses[key].Value.Content)
+		}
+	}
+}
+
+func Test_CompileExamples(t *testing.T) {
+	doc := newTestDoc()
+	minor := doc.Example("minor employee", Employee{Name: "Tim"})
+	doc.Post(&Endpoint{
+		Path: "/employees",
+		ReqBody: ReqBody(doc.Schema(Employee{}))(CONTENT_TYPE_JSON, CONTENT_TYPE_XML).
+			WithExample("valid", Employee{Name: "John"}).
+			WithExamples(minor),
+		QueryParams: QueryParams(
+			OptionalParam("team", doc.Schema("")).WithExample("core", "core").WithExamples(doc.Example("empty", "")),
+		),
+		RespSet: RespSet{
+			Success: ResJson("Created", doc.Schema(Employee{})).WithExamples(minor),
+		},
+	})
+
+	cd, err := doc.Compile()
+	if err != nil {
+		t.Fatalf("error while compiling doc, %v", err)
+	}
+
+	op := cd.specs.Paths["/employees"].Post
+	for _, ct := range []string{"application/json", "application/xml"} {
+		examples := op.RequestBody.Value.Content[ct].Examples
+		if valid := examples["valid"]; valid == nil || valid.Ref != "" || valid.Value.Value.(Employee).Name != "John" {
+			t.Errorf("%s inline example not match, got=%v", ct, valid)
+		}
+		if ref := examples["minor employee"]; ref == nil || ref.Ref != "#/components/examples/minor_employee" {
+			t.Errorf("%s component example should be referred, got=%v", ct, ref)
+		}
+	}
+	if ref := op.Responses["200"].Value.Content["application/json"].Examples["minor employee"]; ref == nil || ref.Ref == "" {
+		t.Errorf("response component example should be referred, got=%v", ref)
+	}
+	if examples := op.Parameters[0].Value.Examples; len(examples) != 2 || examples["core"].Value.Value != "core" {
+		t.Errorf("parameter examples not match, got=%v", examples)
+	}
+	components := cd.specs.Components.Examples
+	if len(components) != 2 || components["minor_employee"] == nil || components["empty"] == nil {
+		t.Errorf("component examples not match, got=%v", components)
+	}
+
+	doc.Get(&Endpoint{
+		Path:    "/teams",
+		RespSet: RespSet{Success: ResJson("Teams", nil).WithExamples(doc.Example("minor employee", nil))},
+	})
+	if _, err := doc.Compile(); err == nil {
+		t.Errorf("component examples with the same name should fail")
+	}
+}
+
+type AvatarUpload struct {
+	Name   string `json:"name" form:"name"`
+	Avatar []byte `json:"avatar" form:"avatar"`
+}
+
+func Test_CompileContent(t *testing.T) {
+	doc := newTestDoc()
+	doc.Post(&Endpoint{
+		Path: "/avatars",
+		ReqBody: ReqJson(doc.Schema(Employee{Name: "John"})).WithExample("john", Employee{Name: "John"}).
+			WithContent(CONTENT_TYPE_MULTIPART, Media(doc.Schema(AvatarUpload{})).
+				WithExample("png", AvatarUpload{Name: "me.png"}).
+				WithEncoding("avatar", &Encoding{
+					ContentTypes: []ContentType{"image/png", "image/jpeg"},
+					Headers:      Parameters{OptionalParam("X-Checksum", doc.Schema(""))},
+				})),
+		RespSet: RespSet{
+			Success: ResJson("Avatars", doc.Schema(Employee{})).WithContent("text/csv", FileMedia()),
+		},
+	})
+
+	cd, err := doc.Compile()
+	if err != nil {
+		t.Fatalf("error while compiling doc, %v", err)
+	}
+
+	op := cd.specs.Paths["/avatars"].Post
+	content := op.RequestBody.Value.Content
+	if json := content["application/json"]; json == nil || json.Schema.Value.Properties["manager"] == nil {
+		t.Errorf("json body should be the employee, got=%v", json)
+	}
+	multipart := content["multipart/form-data"]
+	if multipart == nil || multipart.Schema.Value.Properties["avatar"] == nil {
+		t.Fatalf("multipart body should be the upload, got=%v", multipart)
+	}
+	if len(multipart.Examples) != 2 || multipart.Examples["john"] == nil || multipart.Examples["png"] == nil {
+		t.Errorf("multipart examples should be the shared and its own examples, got=%v", multipart.Examples)
+	}
+	avatar := multipart.Encoding["avatar"]
+	if avatar == nil || avatar.ContentType != "image/png, image/jpeg" || avatar.Headers["X-Checksum"] == nil {
+		t.Errorf("avatar encoding not match, got=%v", avatar)
+	}
+
+	resp := op.Responses["200"].Value.Content
+	if csv := resp["text/csv"]; csv == nil || csv.Schema.Value.Format != "binary" {
+		t.Errorf("csv response should be binary, got=%v", csv)
+	}
+	if resp["application/json"] == nil {
+		t.Errorf("json response should be kept")
+	}
+}
+
+type ProfileForm struct {
+	Name   string `form:"name"`
+	Avatar File   `form:"avatar"`
+	Photos []File `form:"photos"`
+	Cover  *File  `form:"cover"`
+}
+
+func Test_CompileMultipart(t *testing.T) {
+	doc := newTestDoc()
+	doc.Post(&Endpoint{
+		Path: "/profiles",
+		ReqBody: ReqMultipart(SchemaOf[ProfileForm](doc)).WithEncoding("avatar", &Encoding{
+			ContentTypes: []ContentType{"image/png"},
+		}),
+		RespSet: RespSet{
+			Success: ResEmpty("Profile saved"),
+		},
+	})
+
+	cd, err := doc.Compile()
+	if err != nil {
+		t.Fatalf("error while compiling doc, %v", err)
+	}
+
+	multipart := cd.specs.Paths["/profiles"].Post.RequestBody.Value.Content["multipart/form-data"]
+	if multipart == nil {
+		t.Fatalf("request body should be multipart")
+	}
+	properties := multipart.Schema.Value.Properties
+	if avatar := properties["avatar"].Value; avatar.Type != "string" || avatar.Format != "binary" || avatar.Example != nil {
+		t.Errorf("avatar should be a binary string, got=%v", avatar)
+	}
+	if photos := properties["photos"].Value; photos.Type != "array" || photos.Items.Value.Format != "binary" {
+		t.Errorf("photos should be an array of binary strings, got=%v", photos)
+	}
+	if cover := properties["cover"].Value; cover.Format != "binary" || cover.Nullable {
+		t.Errorf("cover should be a binary string which is not nullable, got=%v", cover)
+	}
+	if example, ok := multipart.Schema.Value.Example.(map[string]interface{}); !ok || example["name"] == nil {
+		t.Errorf("multipart example should be the form, got=%v", multipart.Schema.Value.Example)
+	} else if _, ok := example["cover"]; ok {
+		t.Errorf("files should be left out of the example, got=%v", example)
+	}
+	if avatar := multipart.Encoding["avatar"]; avatar == nil || avatar.ContentType != "image/png" {
+		t.Errorf("avatar encoding not match, got=%v", avatar)
+	}
+}
+
+func Test_CompileEncodingContentTypes(t *testing.T) {
+	avatar := &Encoding{ContentTypes: []ContentType{"image/png"}}
+
+	doc := newTestDoc()
+	doc.Post(&Endpoint{
+		Path:    "/profiles",
+		ReqBody: ReqBody(SchemaOf[ProfileForm](doc))(CONTENT_TYPE_JSON, CONTENT_TYPE_MULTIPART).WithEncoding("avatar", avatar),
+		RespSet: RespSet{Success: ResEmpty("Profile saved")},
+	})
+	cd, err := doc.Compile()
+	if err != nil {
+		t.Fatalf("error while compiling doc, %v", err)
+	}
+	content := cd.specs.Paths["/profiles"].Post.RequestBody.Value.Content
+	if json := content["application/json"]; json.Encoding != nil {
+		t.Errorf("json body should have no encoding, got=%v", json.Encoding)
+	}
+	if multipart := content["multipart/form-data"]; multipart.Encoding["avatar"] == nil {
+		t.Errorf("multipart body should have the encoding, got=%v", multipart.Encoding)
+	}
+
+	bodies := []RequestBody{
+		ReqJson(nil).WithEncoding("avatar", avatar),
+		ReqJson(nil).WithContent(CONTENT_TYPE_XML, Media(nil).WithEncoding("avatar", avatar)),
+	}
+	for _, body := range bodies {
+		doc := newTestDoc()
+		doc.Post(&Endpoint{
+			Path:    "/profiles",
+			ReqBody: body,
+			RespSet: RespSet{Success: ResEmpty("Profile saved")},
+		})
+		if _, err := doc.Compile(); err == nil {
+			t.Errorf("encodings of %v should fail", body.ContentTypes)
+		}
+	}
+}
+
+type Tag struct {
+	Name string `json:"name"`
+}
+
+type Post struct {
+	Title string `json:"title"`
+	Tags  []Tag  `json:"tags"`
+}
+
+func Test_CompileComponentNestedStruct(t *testing.T) {
+	doc := newTestDoc()
+	doc.Component(Post{Title: "component"})
+	doc.Post(&Endpoint{
+		Path:    "/posts",
+		ReqBody: ReqJson(doc.Schema(Post{Title: "inline", Tags: []Tag{{Name: "go"}}})),
+		RespSet: RespSet{
+			Success: ResJson("Post", doc.Schema(Post{})),
+		},
+	})
+
+	cd, err := doc.Compile()
+	if err != nil {
+		t.Fatalf("error while compiling doc, %v", err)
+	}
+
+	items := cd.specs.Components.Schemas["Post"].Value.Properties["tags"].Value.Items.Value
+	if items.Type != "object" || items.Properties["name"] == nil {
+		t.Errorf("tags of the component should be explored, got=%v", items)
+	}
+}
+
+type Squad struct {
+	Owner  User  `json:"owner" qd:"desc='squad owner',readOnly"`
+	Backup *User `json:"backup" qd:"deprecated"`
+	Coach  User  `json:"coach"`
+}
+
+func Test_CompileComponentFieldMetadata(t *testing.T) {
+	doc := newTestDoc()
+	doc.Get(&Endpoint{
+		Path: "/squads",
+		RespSet: RespSet{
+			Success: ResJson("Squad", doc.Schema(Squad{})),
+		},
+	})
+	doc.Component(User{})
+
+	cd, err := doc.Compile()
+	if err != nil {
+		t.Fatalf("error while compiling doc, %v", err)
+	}
+
+	ref := "#/components/schemas/User"
+	properties := cd.specs.Paths["/squads"].Get.Responses["200"].Value.Content["application/json"].Schema.Value.Properties
+	owner := properties["owner"].Value
+	if len(owner.AllOf) != 1 || owner.AllOf[0].Ref != ref || owner.Description != "squad owner" || !owner.ReadOnly {
+		t.Errorf("owner should be a wrapped reference with its metadata, got=%v", owner)
+	}
+	backup := properties["backup"].Value
+	if len(backup.AllOf) != 1 || !backup.Deprecated || !backup.Nullable {
+		t.Errorf("backup should be a wrapped reference with its metadata, got=%v", backup)
+	}
+	if coach := properties["coach"]; coach.Ref != ref {
+		t.Errorf("coach should be a plain reference, got=%v", coach)
+	}
+	if user := cd.specs.Components.Schemas["User"].Value; user.Description != "" || user.ReadOnly || user.Nullable {
+		t.Errorf("component should not take metadata of a field, got=%v", user)
+	}
+}
+
+func Test_HttpStatusString(t *testing.T) {
+	tests := map[HttpStatus]string{
+		HTTP_OK:      "200",
+		HTTP_CREATED: "201",
+		HTTP_5XX:     "5XX",
+		HTTP_DEFAULT: "default",
+	}
+	for status, want := range tests {
+		if got := status.String(); got != want {
+			t.Errorf("status string not match, got=%s want=%s", got, want)
+		}
+	}
+}
+
+type Route struct {
+	Stops [3]string `json:"stops"`
+	Hash  [2]byte   `json:"hash"`
+}
+
+func Test_CompileArray(t *testing.T) {
+	doc := newTestDoc()
+	doc.Get(&Endpoint{
+		Path: "/routes",
+		RespSet: RespSet{
+			Success: ResJson("Route", doc.Schema(Route{})),
+		},
+	})
+
+	cd, err := doc.Compile()
+	if err != nil {
+		t.Fatalf("error while compiling doc, %v", err)
+	}
+
+	sc := cd.specs.Paths["/routes"].Get.Responses.Get(200).Value.Content.Get(string(CONTENT_TYPE_JSON)).Schema.Value
+	stops := sc.Properties["stops"].Value
+	if stops.MinItems != 3 || stops.MaxItems == nil || *stops.MaxItems != 3 {
+		t.Errorf("stops should have 3 items, got min=%d max=%v", stops.MinItems, stops.MaxItems)
+	}
+	hash := sc.Properties["hash"].Value
+	if hash.Type != "string" || hash.MinLength != 4 || hash.MaxLength == nil || *hash.MaxLength != 4 {
+		t.Errorf("hash should be a fixed length string, got=%v", hash)
+	}
+}
+
+func Test_CompileMixedSlice(t *testing.T) {
+	doc := newTestDoc()
+	doc.Get(&Endpoint{
+		Path: "/feed",
+		RespSet: RespSet{
+			Success: ResJson("Feed", doc.Schema([]interface{}{"text", 42, User{Username: "john"}})),
+		},
+	})
+
+	cd, err := doc.Compile()
+	if err != nil {
+		t.Fatalf("error while compiling doc, %v", err)
+	}
+
+	sc := cd.specs.Paths["/feed"].Get.Responses.Get(200).Value.Content.Get(string(CONTENT_TYPE_JSON)).Schema.Value
+	if len(sc.Items.Value.OneOf) != 3 {
+		t.Errorf("items should be one of the element types, got=%v", sc.Items.Value)
+	}
+	example, err := json.Marshal(sc.Example)
+	if err != nil {
+		t.Fatalf("error while marshaling example, %v", err)
+	}
+	if want := `["text",42,{"age":0,"username":"john"}]`; string(example) != want {
+		t.Errorf("example not match, got=%s want=%s", example, want)
+	}
+}
+
+type benchAddress struct {
+	Street  string `json:"street" validate:"required,max=120"`
+	City    string `json:"city" qd:"desc='city name',example=Colombo"`
+	Country string `json:"country" validate:"oneof=LK IN"`
+	Geo     *struct {
+		Lat float64 `json:"lat"`
+		Lng float64 `json:"lng"`
+	} `json:"geo"`
+}
+
+type benchCustomer struct {
+	ID        int64           `json:"id"`
+	Name      string          `json:"name" validate:"required,min=3"`
+	Email     string          `json:"email" validate:"email"`
+	Addresses []benchAddress  `json:"addresses"`
+	Billing   *benchAddress   `json:"billing"`
+	Tags      []string        `json:"tags" validate:"unique"`
+	Meta      map[string]bool `json:"meta"`
+}
+
+type benchOrder struct {
+	ID       int64            `json:"id"`
+	Customer benchCustomer    `json:"customer"`
+	Items    []benchOrderItem `json:"items"`
+	Previous *benchOrder      `json:"previous"`
+}
+
+type benchOrderItem struct {
+	SKU      string        `json:"sku"`
+	Quantity int           `json:"quantity" validate:"min=1"`
+	Price    float64       `json:"price"`
+	Customer benchCustomer `json:"customer"`
+}
+
+func benchmarkCompile500(b *testing.B, cached bool) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		doc := newTestDoc()
+		if !cached {
+			doc.SchemaOptions().Cache = nil
+		}
+		order := doc.Component(benchOrder{})
+		for e := 0; e < 500; e++ {
+			doc.Post(&Endpoint{
+				Path:    fmt.Sprintf("/orders/%d", e),
+				ReqBody: ReqJson(doc.Schema(benchOrder{Items: []benchOrderItem{{}, {}}})),
+				RespSet: RespSet{
+					Success: ResJson("Order", order),
+				},
+			})
+		}
+		if _, err := doc.Compile(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCompile500(b *testing.B) {
+	benchmarkCompile500(b, true)
+}
+
+func BenchmarkCompile500Uncached(b *testing.B) {
+	benchmarkCompile500(b, false)
+}
@@ -0,0 +1,212 @@
+package qdoc
+
+import (
+	"fmt"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/pickme-lk/quick-doc/schema"
+	"path"
+	"strings"
+)
+
+// components collects reusable schemas while the document is being compiled.
+// Schemas and references are keyed by schema.Property.Ref, references are
+// resolved once every path has been compiled. Schemas named by a struct tag
+// other than the default one are kept as separate components (see withNameTag).
+type components struct {
+	schemas        map[string]*openapi3.Schema
+	refs           map[string][]*openapi3.SchemaRef
+	discriminators map[*openapi3.Discriminator]bool
+	examples       map[string]*Example
+	defaultTag     string
+	nameTag        string
+}
+
+func newComponents(defaultTag string) *components {
+	return &components{
+		schemas:        make(map[string]*openapi3.Schema),
+		refs:           make(map[string][]*openapi3.SchemaRef),
+		discriminators: make(map[*openapi3.Discriminator]bool),
+		examples:       make(map[string]*Example),
+		defaultTag:     defaultTag,
+	}
+}
+
+// withNameTag returns the components which document schemas named by the
+// struct tag, registered schemas are shared
+func (c *components) withNameTag(tag string) *components {
+	_c := *c
+	_c.nameTag = tag
+	if tag == c.defaultTag {
+		_c.nameTag = ""
+	}
+	return &_c
+}
+
+// key returns the key of the type reference, qualified by the name tag
+func (c *components) key(ref string) string {
+	if c.nameTag == "" {
+		return ref
+	}
+	return ref + "|" + c.nameTag
+}
+
+// has reports whether a schema has been registered for the type reference
+func (c *components) has(ref string) bool {
+	_, ok := c.schemas[c.key(ref)]
+	return ok
+}
+
+// register adds the schema of the type reference, first registration wins
+func (c *components) register(ref string, sc *openapi3.Schema) {
+	if !c.has(ref) {
+		c.schemas[c.key(ref)] = sc
+	}
+}
+
+// schemaRef returns a reference to the component, resolved by compile
+func (c *components) schemaRef(ref string) *openapi3.SchemaRef {
+	sr := &openapi3.SchemaRef{}
+	c.refs[c.key(ref)] = append(c.refs[c.key(ref)], sr)
+	return sr
+}
+
+// discriminator returns the discriminator of the union, mapped type references
+// are resolved by compile
+func (c *components) discriminator(d *schema.Discriminator) *openapi3.Discriminator {
+	_d := &openapi3.Discriminator{
+		PropertyName: d.PropertyName,
+	}
+	if len(d.Mapping) > 0 {
+		_d.Mapping = make(map[string]string)
+	}
+	for value, ref := range d.Mapping {
+		_d.Mapping[value] = c.key(ref)
+	}
+	c.discriminators[_d] = true
+	return _d
+}
+
+// exampleRef returns the example, component examples are registered and referred
+func (c *components) exampleRef(ex *Example) (*openapi3.ExampleRef, error) {
+	if !ex.component {
+		return &openapi3.ExampleRef{Value: ex.toOpenAPI()}, nil
+	}
+	name := componentName(ex.Name)
+	if registered, ok := c.examples[name]; ok && registered != ex {
+		return nil, fmt.Errorf("component example %q is defined twice", name)
+	}
+	c.examples[name] = ex
+	return &openapi3.ExampleRef{
+		Ref:   "#/components/examples/" + name,
+		Value: ex.toOpenAPI(),
+	}, nil
+}
+
+// compileExamples returns components/examples
+func (c *components) compileExamples() openapi3.Examples {
+	if len(c.examples) == 0 {
+		return nil
+	}
+	examples := make(openapi3.Examples)
+	for name, ex := range c.examples {
+		examples[name] = &openapi3.ExampleRef{Value: ex.toOpenAPI()}
+	}
+	return examples
+}
+
+// compile resolves all the references and returns components/schemas, every
+// discriminator mapping must refer to a component
+func (c *components) compile() (openapi3.Schemas, error) {
+	names := c.names()
+	schemas := make(openapi3.Schemas)
+	for ref, sc := range c.schemas {
+		name := names[ref]
+		schemas[name] = openapi3.NewSchemaRef("", sc)
+		for _, sr := range c.refs[ref] {
+			sr.Ref = "#/components/schemas/" + name
+			sr.Value = sc
+		}
+	}
+	for d := range c.discriminators {
+		for value, ref := range d.Mapping {
+			name, ok := names[ref]
+			if !ok {
+				typeRef := strings.Split(ref, "|")[0]
+				return nil, fmt.Errorf("discriminator %s: %s of %q is not a member of the union or a component", d.PropertyName, typeRef, value)
+			}
+			d.Mapping[value] = "#/components/schemas/" + name
+		}
+	}
+	return schemas, nil
+}
+
+// names returns the component name of each registered type reference. Type
+// names are used as they are, types which share the same name are qualified
+// with their package name, or the full package path when it is still ambiguous.
+// Schemas of other name tags are suffixed by the tag. Ex: User_xml
+func (c *components) names() map[string]string {
+	byName := make(map[string][]string)
+	for ref := range c.schemas {
+		_, name := splitKey(ref)
+		byName[name] = append(byName[name], ref)
+	}
+
+	byPkgName := make(map[string]int)
+	for ref := range c.schemas {
+		pkg, name := splitKey(ref)
+		byPkgName[path.Base(pkg)+"."+name]++
+	}
+
+	names := make(map[string]string)
+	for name, refs := range byName {
+		for _, ref := range refs {
+			pkg, _ := splitKey(ref)
+			switch {
+			case len(refs) == 1:
+				names[ref] = componentName(name)
+			case byPkgName[path.Base(pkg)+"."+name] == 1:
+				names[ref] = componentName(path.Base(pkg) + "." + name)
+			default:
+				names[ref] = componentName(strings.ReplaceAll(pkg, "/", ".") + "." + name)
+			}
+		}
+	}
+	return names
+}
+
+// splitKey splits a components key into the package path and the name tag qualified type name
+func splitKey(key string) (pkg string, name string) {
+	tag := ""
+	if i := strings.LastIndex(key, "|"); i >= 0 {
+		key, tag = key[:i], key[i+1:]
+	}
+	pkg, name = splitRef(key)
+	if tag != "" {
+		name += "_" + tag
+	}
+	return pkg, name
+}
+
+// splitRef splits a schema.TypeRef into the package path and the type name
+func splitRef(ref string) (pkg string, name string) {
+	// type parameters of generic types may contain qualified names as well
+	end := strings.Index(ref, "[")
+	if end < 0 {
+		end = len(ref)
+	}
+	i := strings.LastIndex(ref[:end], ".")
+	if i < 0 {
+		return "", ref
+	}
+	return ref[:i], ref[i+1:]
+}
+
+// componentName replaces characters which are not allowed in component names
+func componentName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+}
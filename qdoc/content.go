@@ -0,0 +1,155 @@
+package qdoc
+
+import (
+	"errors"
+	"fmt"
+	"github.com/getkin/kin-openapi/openapi3"
+	"strings"
+)
+
+// MediaType is the body of a single content type, used when content types of
+// a request body or response have different schemas
+type MediaType struct {
+	Schema   *SchemaConfig
+	Examples Examples
+	Encoding map[string]*Encoding // multipart and form parts by property name
+	raw      *openapi3.Schema     // schema of bodies which are not encoded objects, Ex: files and text
+}
+
+// Encoding describes a single part of a multipart or form body
+type Encoding struct {
+	ContentTypes []ContentType // allowed content types of the part, Ex: image/png
+	Headers      Parameters    // headers of the part, multipart only
+}
+
+// Media returns the media type of the schema
+func Media(sc *SchemaConfig) *MediaType {
+	return &MediaType{
+		Schema: sc,
+	}
+}
+
+// FileMedia returns the media type of a binary body, Ex: text/csv downloads
+func FileMedia() *MediaType {
+	return &MediaType{
+		raw: &openapi3.Schema{Type: "string", Format: "binary"},
+	}
+}
+
+// TextMedia returns the media type of a string body
+func TextMedia() *MediaType {
+	return &MediaType{
+		raw: openapi3.NewStringSchema(),
+	}
+}
+
+// WithExample adds a named example to the media type
+func (mt *MediaType) WithExample(name string, value interface{}) *MediaType {
+	return mt.WithExamples(NewExample(name, value))
+}
+
+// WithExamples adds named examples to the media type, see Example
+func (mt *MediaType) WithExamples(examples ...*Example) *MediaType {
+	mt.Examples = mt.Examples.with(examples...)
+	return mt
+}
+
+// WithEncoding describes the part of the property. Ex:
+// qdoc.Media(sc).WithEncoding("avatar", &qdoc.Encoding{ContentTypes: []qdoc.ContentType{"image/png"}})
+func (mt *MediaType) WithEncoding(property string, encoding *Encoding) *MediaType {
+	if mt.Encoding == nil {
+		mt.Encoding = make(map[string]*Encoding)
+	}
+	mt.Encoding[property] = encoding
+	return mt
+}
+
+// toOpenAPI converts the media type of the content type, properties are named
+// by the struct tag of the content type (see ContentType.NameTag). Shared
+// examples come before the examples of the media type.
+func (mt *MediaType) toOpenAPI(c *components, ct ContentType, shared Examples) (*openapi3.MediaType, error) {
+	ref := openapi3.NewSchemaRef("", mt.raw)
+	if mt.raw == nil || mt.Schema != nil {
+		var err error
+		if ref, err = mt.Schema.toOpenAPI(c.withNameTag(ct.NameTag())); err != nil {
+			return nil, err
+		}
+	}
+	_mt := openapi3.NewMediaType().WithSchemaRef(ref)
+	examples, err := shared.with(mt.Examples...).toOpenAPI(c)
+	if err != nil {
+		return nil, err
+	}
+	_mt.Examples = examples
+	if !ct.encodable() {
+		return _mt, nil
+	}
+	for property, encoding := range mt.Encoding {
+		_encoding, err := encoding.toOpenAPI(c)
+		if err != nil {
+			return nil, fmt.Errorf("encoding %s: %w", property, err)
+		}
+		_mt.WithEncoding(property, _encoding)
+	}
+	return _mt, nil
+}
+
+func (e *Encoding) toOpenAPI(c *components) (*openapi3.Encoding, error) {
+	contentTypes := make([]string, len(e.ContentTypes))
+	for i, ct := range e.ContentTypes {
+		contentTypes[i] = string(ct)
+	}
+	encoding := &openapi3.Encoding{
+		ContentType: strings.Join(contentTypes, ", "),
+	}
+	for _, header := range e.Headers {
+		_header, err := header.toHeader(c)
+		if err != nil {
+			return nil, err
+		}
+		encoding.WithHeader(header.Name, _header)
+	}
+	return encoding, nil
+}
+
+// toContent converts the body of every content type. Content types of the list
+// share the body, media types of the content map have their own and take
+// precedence. Shared examples are added to every content type, encodings only
+// to multipart and form content types.
+func toContent(c *components, body *MediaType, contentTypes []ContentType, media map[ContentType]*MediaType) (openapi3.Content, error) {
+	if len(body.Encoding) > 0 && !anyEncodable(contentTypes) {
+		return nil, errors.New("encodings need a multipart or form content type")
+	}
+	content := openapi3.NewContent()
+	for _, ct := range contentTypes {
+		if _, ok := media[ct]; ok {
+			continue
+		}
+		mt, err := body.toOpenAPI(c, ct, nil)
+		if err != nil {
+			return nil, err
+		}
+		content[string(ct)] = mt
+	}
+	for ct, mt := range media {
+		if len(mt.Encoding) > 0 && !ct.encodable() {
+			return nil, fmt.Errorf("%s: encodings need a multipart or form content type", ct)
+		}
+		_mt, err := mt.toOpenAPI(c, ct, body.Examples)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", ct, err)
+		}
+		content[string(ct)] = _mt
+	}
+	return content, nil
+}
+
+// anyEncodable reports whether any of the content types can have encodings
+func anyEncodable(contentTypes []ContentType) bool {
+	for _, ct := range contentTypes {
+		if ct.encodable() {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,11 @@
 package qdoc
 
-import "github.com/pickme-lk/quick-doc/ui"
+import (
+	"github.com/pickme-lk/quick-doc/schema"
+	"github.com/pickme-lk/quick-doc/ui"
+	"reflect"
+	"strings"
+)
 
 // MethodType Http methods
 type MethodType string
@@ -20,8 +25,40 @@ const (
 	CONTENT_TYPE_MULTIPART = ContentType("multipart/form-data")
 	CONTENT_TYPE_HTML      = ContentType("text/html")
 	CONTENT_TYPE_FILE      = ContentType("application/octet-stream")
+	CONTENT_TYPE_XML       = ContentType("application/xml")
+	CONTENT_TYPE_YAML      = ContentType("application/yaml")
+	CONTENT_TYPE_MSGPACK   = ContentType("application/msgpack")
+	CONTENT_TYPE_TEXT      = ContentType("text/plain")
+	CONTENT_TYPE_STREAM    = ContentType("text/event-stream")
 )
 
+// NameTag returns the struct tag which names the properties of the content
+// type, empty when the content type has no struct tag of its own
+func (ct ContentType) NameTag() string {
+	mediaType := strings.TrimSpace(strings.Split(string(ct), ";")[0])
+	switch {
+	case mediaType == string(CONTENT_TYPE_JSON) || strings.HasSuffix(mediaType, "+json"):
+		return "json"
+	case mediaType == string(CONTENT_TYPE_FORM) || mediaType == string(CONTENT_TYPE_MULTIPART):
+		return "form"
+	case mediaType == string(CONTENT_TYPE_XML) || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml"):
+		return "xml"
+	case mediaType == string(CONTENT_TYPE_YAML) || mediaType == "application/x-yaml" || strings.HasSuffix(mediaType, "+yaml"):
+		return "yaml"
+	case mediaType == string(CONTENT_TYPE_MSGPACK) || mediaType == "application/x-msgpack":
+		return "msgpack"
+	default:
+		return ""
+	}
+}
+
+// encodable reports whether parts of the content type can be described by
+// encodings, only multipart and form bodies have parts
+func (ct ContentType) encodable() bool {
+	mediaType := strings.TrimSpace(strings.Split(string(ct), ";")[0])
+	return mediaType == string(CONTENT_TYPE_FORM) || strings.HasPrefix(mediaType, "multipart/")
+}
+
 type UiConfig struct {
 	Enabled      bool
 	Path         string
@@ -60,9 +97,10 @@ type Endpoint struct {
 }
 
 type Doc struct {
-	config    Config
-	endpoints []*Endpoint
-	schemas   []*SchemaConfig
+	config     Config
+	endpoints  []*Endpoint
+	schemas    []*SchemaConfig
+	schemaOpts *schema.Options
 }
 
 func NewDoc(config Config) *Doc {
@@ -87,7 +125,35 @@ func NewDoc(config Config) *Doc {
 	return &Doc{
 		config:    config,
 		endpoints: make([]*Endpoint, 0),
+		schemaOpts: &schema.Options{
+			ExploreNilStruct:  false,
+			PreferJsonTag:     true,
+			TagPrefix:         schema.DefaultTagPrefix,
+			IgnoreUnexported:  true,
+			RespectJsonIgnore: true,
+			RespectOmitempty:  true,
+			OptionalPointers:  true,
+			Cache:             schema.NewCache(),
+			RefTypes:          make(map[reflect.Type]bool),
+			TypeMappings: map[reflect.Type]schema.TypeMapping{
+				reflect.TypeOf(File{}): schema.FileMapping,
+			},
+		},
+	}
+}
+
+// SchemaOptions returns the schema builder options shared by every schema of
+// the document, options can be changed before compiling the document
+func (d *Doc) SchemaOptions() *schema.Options {
+	return d.schemaOpts
+}
+
+// nameTag returns the struct tag which names the properties by default
+func (d *Doc) nameTag() string {
+	if d.schemaOpts.NameTag == "" {
+		return schema.DefaultNameTag
 	}
+	return d.schemaOpts.NameTag
 }
 
 func (d *Doc) addEndpoint(ep *Endpoint) *Endpoint {
@@ -0,0 +1,70 @@
+package qdoc
+
+import (
+	"fmt"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Example is a named example value of a request body, response or parameter.
+// Examples of NewExample are documented inline, examples of Doc.Example are
+// added to components/examples once and referred by every usage.
+type Example struct {
+	Name        string
+	Summary     string
+	Description string
+	Value       interface{}
+	component   bool
+}
+
+type Examples []*Example
+
+// NewExample returns a named example which is documented inline
+func NewExample(name string, value interface{}) *Example {
+	return &Example{
+		Name:  name,
+		Value: value,
+	}
+}
+
+// Example returns a named example which is added to components/examples and
+// referred by every usage. Ex: doc.Example("minor", User{Age: 12})
+func (d *Doc) Example(name string, value interface{}) *Example {
+	return &Example{
+		Name:      name,
+		Value:     value,
+		component: true,
+	}
+}
+
+// with returns the examples with the example appended, the receiver is not modified
+func (e Examples) with(examples ...*Example) Examples {
+	return append(e[:len(e):len(e)], examples...)
+}
+
+// toOpenAPI converts the examples into an examples map keyed by the example
+// names, component examples are referred
+func (e Examples) toOpenAPI(c *components) (openapi3.Examples, error) {
+	if len(e) == 0 {
+		return nil, nil
+	}
+	examples := make(openapi3.Examples)
+	for _, ex := range e {
+		if _, ok := examples[ex.Name]; ok {
+			return nil, fmt.Errorf("example %q is defined twice", ex.Name)
+		}
+		ref, err := c.exampleRef(ex)
+		if err != nil {
+			return nil, err
+		}
+		examples[ex.Name] = ref
+	}
+	return examples, nil
+}
+
+func (ex *Example) toOpenAPI() *openapi3.Example {
+	return &openapi3.Example{
+		Summary:     ex.Summary,
+		Description: ex.Description,
+		Value:       ex.Value,
+	}
+}
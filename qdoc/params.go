@@ -1,6 +1,7 @@
 package qdoc
 
 import (
+	"fmt"
 	"github.com/getkin/kin-openapi/openapi3"
 )
 
@@ -18,6 +19,7 @@ type Parameter struct {
 	Description string
 	Required    bool
 	Loc         ParamType
+	Examples    Examples
 }
 
 type Parameters []Parameter
@@ -64,12 +66,57 @@ func OptionalParam(name string, value *SchemaConfig) Parameter {
 	}
 }
 
-func (p *Parameter) toOpenAPI() *openapi3.Parameter {
+// WithExample returns the parameter with a named example.
+// Ex: qdoc.RequiredParam("status", sc).WithExample("active", 1)
+func (p Parameter) WithExample(name string, value interface{}) Parameter {
+	return p.WithExamples(NewExample(name, value))
+}
+
+// WithExamples returns the parameter with the named examples, see Example
+func (p Parameter) WithExamples(examples ...*Example) Parameter {
+	p.Examples = p.Examples.with(examples...)
+	return p
+}
+
+func (p *Parameter) toOpenAPI(c *components) (*openapi3.Parameter, error) {
+	if p.Loc == PARAM_TYPE_QUERY {
+		// query parameters are bound same as form values
+		c = c.withNameTag(CONTENT_TYPE_FORM.NameTag())
+	}
+	sc, err := p.Scheme.toOpenAPI(c)
+	if err != nil {
+		return nil, fmt.Errorf("%s parameter %s: %w", p.Loc, p.Name, err)
+	}
+	examples, err := p.Examples.toOpenAPI(c)
+	if err != nil {
+		return nil, fmt.Errorf("%s parameter %s: %w", p.Loc, p.Name, err)
+	}
 	return &openapi3.Parameter{
 		Name:        p.Name,
 		In:          string(p.Loc),
 		Description: p.Description,
 		Required:    p.Required,
-		Schema:      openapi3.NewSchemaRef("", p.Scheme.toOpenAPI()),
+		Schema:      sc,
+		Examples:    examples,
+	}, nil
+}
+
+// toHeader converts the parameter into a response header, the name is the key of the headers map
+func (p *Parameter) toHeader(c *components) (*openapi3.Header, error) {
+	sc, err := p.Scheme.toOpenAPI(c)
+	if err != nil {
+		return nil, fmt.Errorf("header %s: %w", p.Name, err)
+	}
+	examples, err := p.Examples.toOpenAPI(c)
+	if err != nil {
+		return nil, fmt.Errorf("header %s: %w", p.Name, err)
 	}
+	return &openapi3.Header{
+		Parameter: openapi3.Parameter{
+			Description: p.Description,
+			Required:    p.Required,
+			Schema:      sc,
+			Examples:    examples,
+		},
+	}, nil
 }
@@ -1,6 +1,7 @@
 package qdoc
 
 import (
+	"fmt"
 	"github.com/getkin/kin-openapi/openapi3"
 	"strconv"
 )
@@ -8,17 +9,84 @@ import (
 type HttpStatus int
 
 var HTTP_OK = HttpStatus(200)
+var HTTP_CREATED = HttpStatus(201)
+var HTTP_ACCEPTED = HttpStatus(202)
+var HTTP_NO_CONTENT = HttpStatus(204)
 var HTTP_BAD_REQUEST = HttpStatus(400)
 var HTTP_UNAUTHORIZED = HttpStatus(401)
 var HTTP_FORBIDDEN = HttpStatus(403)
 var HTTP_NOT_FOUND = HttpStatus(404)
+var HTTP_CONFLICT = HttpStatus(409)
+var HTTP_UNPROCESSABLE_ENTITY = HttpStatus(422)
+var HTTP_TOO_MANY_REQUESTS = HttpStatus(429)
 var HTTP_ISE = HttpStatus(500)
+var HTTP_SERVICE_UNAVAILABLE = HttpStatus(503)
+
+// status ranges, Ex: HTTP_4XX documents every 4xx status without a response of its own
+var HTTP_1XX = HttpStatus(1)
+var HTTP_2XX = HttpStatus(2)
+var HTTP_3XX = HttpStatus(3)
+var HTTP_4XX = HttpStatus(4)
+var HTTP_5XX = HttpStatus(5)
+
+// HTTP_DEFAULT documents every status without a response of its own
+var HTTP_DEFAULT = HttpStatus(-1)
+
+// String returns the OpenAPI responses key of the status. Ex: 201, 4XX, default
+func (s HttpStatus) String() string {
+	switch {
+	case s == HTTP_DEFAULT:
+		return "default"
+	case s >= HTTP_1XX && s <= HTTP_5XX:
+		return strconv.Itoa(int(s)) + "XX"
+	default:
+		return strconv.Itoa(int(s))
+	}
+}
+
+// valid reports whether the status is a status code, a status range or the default
+func (s HttpStatus) valid() bool {
+	return s == HTTP_DEFAULT || (s >= HTTP_1XX && s <= HTTP_5XX) || (s >= 100 && s <= 599)
+}
 
 type Response struct {
 	Status       HttpStatus
 	ContentTypes []ContentType
 	Schema       *SchemaConfig
 	Description  string
+	Headers      Parameters                 // response headers, Ex: Location, ETag
+	Examples     Examples                   // named examples of every content type
+	Content      map[ContentType]*MediaType // content types with a body of their own
+	raw          *openapi3.Schema           // schema of bodies which are not encoded objects, Ex: files and text
+}
+
+// WithHeaders documents the response headers, Ex:
+// qdoc.ResJson("Created", sc).WithHeaders(qdoc.RequiredParam("Location", qdoc.SchemaOf[string](doc)))
+func (r *Response) WithHeaders(headers ...Parameter) *Response {
+	r.Headers = append(r.Headers, headers...)
+	return r
+}
+
+// WithExample adds a named example to every content type of the response.
+// Ex: qdoc.ResJson("User found", sc).WithExample("admin", User{Role: "admin"})
+func (r *Response) WithExample(name string, value interface{}) *Response {
+	return r.WithExamples(NewExample(name, value))
+}
+
+// WithExamples adds named examples to every content type of the response, see Example
+func (r *Response) WithExamples(examples ...*Example) *Response {
+	r.Examples = r.Examples.with(examples...)
+	return r
+}
+
+// WithContent adds a body of its own for the content type. Ex:
+// qdoc.ResJson("Report", sc).WithContent(qdoc.ContentType("text/csv"), qdoc.FileMedia())
+func (r *Response) WithContent(ct ContentType, mt *MediaType) *Response {
+	if r.Content == nil {
+		r.Content = make(map[ContentType]*MediaType)
+	}
+	r.Content[ct] = mt
+	return r
 }
 
 type RespSet struct {
@@ -31,6 +99,19 @@ type RespSet struct {
 	others    map[HttpStatus]*Response
 }
 
+// With returns a copy of the set with the response of the status. Statuses
+// without a field of their own, status ranges and HTTP_DEFAULT are set this way.
+// Ex: qdoc.RespSet{BadReq: ...}.With(qdoc.HTTP_CREATED, qdoc.ResJson("Created", sc))
+func (r RespSet) With(status HttpStatus, resp *Response) RespSet {
+	others := make(map[HttpStatus]*Response, len(r.others)+1)
+	for k, v := range r.others {
+		others[k] = v
+	}
+	others[status] = resp
+	r.others = others
+	return r
+}
+
 func (r *RespSet) collectToMap() map[HttpStatus]*Response {
 	var m = make(map[HttpStatus]*Response)
 	if r.Success != nil {
@@ -52,20 +133,15 @@ func (r *RespSet) collectToMap() map[HttpStatus]*Response {
 		m[HTTP_ISE] = r.ISE
 	}
 	for k, v := range r.others {
-		m[k] = v
+		if v != nil {
+			m[k] = v
+		}
 	}
 
 	// set status codes
 	for k, v := range m {
 		v.Status = k
 	}
-
-	// remove invalids
-	for k, v := range m {
-		if v.Status == 0 || v.ContentTypes == nil || len(v.ContentTypes) == 0 {
-			delete(m, k)
-		}
-	}
 	return m
 }
 
@@ -78,26 +154,95 @@ func ResJson(desc string, sc *SchemaConfig) *Response {
 	}
 }
 
-func (r Response) toOpenAPI() *openapi3.Response {
-	consumes := make([]string, 0)
-	for _, ct := range r.ContentTypes {
-		consumes = append(consumes, string(ct))
+// ResEmpty returns a Response without a body, Ex: 204 No Content
+func ResEmpty(desc string) *Response {
+	return &Response{
+		Description: desc,
+	}
+}
+
+// ResFile returns a Response of a file download, the content type is
+// application/octet-stream when no content types are given
+func ResFile(desc string, contentTypes ...ContentType) *Response {
+	if len(contentTypes) == 0 {
+		contentTypes = []ContentType{CONTENT_TYPE_FILE}
 	}
-	return &openapi3.Response{
+	return &Response{
+		ContentTypes: contentTypes,
+		Description:  desc,
+		raw:          &openapi3.Schema{Type: "string", Format: "binary"},
+	}
+}
+
+// ResText returns a Response with a plain text body
+func ResText(desc string) *Response {
+	return &Response{
+		ContentTypes: []ContentType{CONTENT_TYPE_TEXT},
+		Description:  desc,
+		raw:          openapi3.NewStringSchema(),
+	}
+}
+
+// ResHTML returns a Response with a html body
+func ResHTML(desc string) *Response {
+	return &Response{
+		ContentTypes: []ContentType{CONTENT_TYPE_HTML},
+		Description:  desc,
+		raw:          openapi3.NewStringSchema(),
+	}
+}
+
+// ResStream returns a Response of server sent events, other streaming
+// content types can be given. Ex: application/x-ndjson
+func ResStream(desc string, contentTypes ...ContentType) *Response {
+	if len(contentTypes) == 0 {
+		contentTypes = []ContentType{CONTENT_TYPE_STREAM}
+	}
+	return &Response{
+		ContentTypes: contentTypes,
+		Description:  desc,
+		raw:          openapi3.NewStringSchema(),
+	}
+}
+
+func (r Response) toOpenAPI(c *components) (*openapi3.Response, error) {
+	resp := &openapi3.Response{
 		Description: &r.Description,
-		Content: openapi3.NewContentWithSchemaRef(
-			openapi3.NewSchemaRef("", r.Schema.toOpenAPI()),
-			consumes,
-		),
 	}
+	if len(r.ContentTypes) > 0 || len(r.Content) > 0 {
+		body := &MediaType{Schema: r.Schema, Examples: r.Examples, raw: r.raw}
+		content, err := toContent(c, body, r.ContentTypes, r.Content)
+		if err != nil {
+			return nil, fmt.Errorf("response %s: %w", r.Status, err)
+		}
+		resp.Content = content
+	}
+	for _, header := range r.Headers {
+		_header, err := header.toHeader(c)
+		if err != nil {
+			return nil, fmt.Errorf("response %s: %w", r.Status, err)
+		}
+		if resp.Headers == nil {
+			resp.Headers = make(openapi3.Headers)
+		}
+		resp.Headers[header.Name] = &openapi3.HeaderRef{Value: _header}
+	}
+	return resp, nil
 }
 
-func (r RespSet) toOpenAPI() openapi3.Responses {
+func (r RespSet) toOpenAPI(c *components) (openapi3.Responses, error) {
 	_responses := make(openapi3.Responses)
 	for _, resp := range r.collectToMap() {
-		_responses[strconv.Itoa(int(resp.Status))] = &openapi3.ResponseRef{
-			Value: resp.toOpenAPI(),
+		if !resp.Status.valid() {
+			return nil, fmt.Errorf("response %s: invalid status", resp.Status)
+		}
+		_resp, err := resp.toOpenAPI(c)
+		if err != nil {
+			return nil, err
+		}
+		_responses[resp.Status.String()] = &openapi3.ResponseRef{
+			Value: _resp,
 		}
 	}
-	return _responses
+	return _responses, nil
 }
@@ -1,11 +1,58 @@
 package qdoc
 
-import "github.com/getkin/kin-openapi/openapi3"
+import (
+	"fmt"
+	"github.com/getkin/kin-openapi/openapi3"
+)
 
 type RequestBody struct {
 	ContentTypes []ContentType
 	Schema       *SchemaConfig
 	Required     bool
+	Examples     Examples                   // named examples of every content type
+	Content      map[ContentType]*MediaType // content types with a body of their own
+	Encoding     map[string]*Encoding       // parts by property name, multipart and form content types only
+}
+
+// File marks a file part of multipart bodies, the part is documented as a
+// binary string. *multipart.FileHeader fields are files too. Ex:
+// Avatar qdoc.File `form:"avatar"`, Photos []qdoc.File `form:"photos"`
+type File struct{}
+
+// WithEncoding returns the request body with the encoding of the part. Ex:
+// qdoc.ReqMultipart(sc).WithEncoding("avatar", &qdoc.Encoding{ContentTypes: []qdoc.ContentType{"image/png"}})
+func (rb RequestBody) WithEncoding(property string, encoding *Encoding) RequestBody {
+	encodings := make(map[string]*Encoding, len(rb.Encoding)+1)
+	for k, v := range rb.Encoding {
+		encodings[k] = v
+	}
+	encodings[property] = encoding
+	rb.Encoding = encodings
+	return rb
+}
+
+// WithContent returns the request body with a body of its own for the content
+// type. Ex: qdoc.ReqJson(sc).WithContent(qdoc.CONTENT_TYPE_MULTIPART, qdoc.Media(upload))
+func (rb RequestBody) WithContent(ct ContentType, mt *MediaType) RequestBody {
+	content := make(map[ContentType]*MediaType, len(rb.Content)+1)
+	for k, v := range rb.Content {
+		content[k] = v
+	}
+	content[ct] = mt
+	rb.Content = content
+	return rb
+}
+
+// WithExample returns the request body with a named example of every content
+// type. Ex: qdoc.ReqJson(sc).WithExample("minor", User{Age: 12})
+func (rb RequestBody) WithExample(name string, value interface{}) RequestBody {
+	return rb.WithExamples(NewExample(name, value))
+}
+
+// WithExamples returns the request body with the named examples, see Example
+func (rb RequestBody) WithExamples(examples ...*Example) RequestBody {
+	rb.Examples = rb.Examples.with(examples...)
+	return rb
 }
 
 func ReqBody(sc *SchemaConfig) func(...ContentType) RequestBody {
@@ -34,23 +81,31 @@ func ReqForm(sc *SchemaConfig) RequestBody {
 	}
 }
 
-func (rb *RequestBody) toOpenAPI() *openapi3.RequestBody {
-	if len(rb.ContentTypes) == 0 {
+// ReqMultipart returns a RequestBody of a multipart form, properties are named
+// by the form tag and File fields are file parts
+func ReqMultipart(sc *SchemaConfig) RequestBody {
+	return RequestBody{
+		ContentTypes: []ContentType{CONTENT_TYPE_MULTIPART},
+		Schema:       sc,
+		Required:     true,
+	}
+}
+
+func (rb *RequestBody) toOpenAPI(c *components) (*openapi3.RequestBody, error) {
+	if len(rb.ContentTypes) == 0 && len(rb.Content) == 0 {
 		return &openapi3.RequestBody{
 			Description: "",
 			Required:    false,
 			Content:     openapi3.NewContent(),
-		}
+		}, nil
 	}
-	consumes := make([]string, 0)
-	for _, ct := range rb.ContentTypes {
-		consumes = append(consumes, string(ct))
+	body := &MediaType{Schema: rb.Schema, Examples: rb.Examples, Encoding: rb.Encoding}
+	content, err := toContent(c, body, rb.ContentTypes, rb.Content)
+	if err != nil {
+		return nil, fmt.Errorf("request body: %w", err)
 	}
 	return &openapi3.RequestBody{
-		Content: openapi3.NewContentWithSchemaRef(
-			openapi3.NewSchemaRef("", rb.Schema.toOpenAPI()),
-			consumes,
-		),
+		Content:  content,
 		Required: rb.Required,
-	}
+	}, nil
 }
@@ -1,45 +1,347 @@
 package qdoc
 
 import (
+	"fmt"
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/pickme-lk/quick-doc/schema"
+	"reflect"
 )
 
 // Schema is document data scheme configuration
 func (d *Doc) Schema(value interface{}) *SchemaConfig {
 	sc := SchemaConfig{
-		Object: value,
-		builder: schema.NewBuilder(&schema.Options{
-			ExploreNilStruct: false,
-			PreferJsonTag:    true,
-		}),
+		Object:  value,
+		builder: schema.NewBuilder(d.schemaOpts),
 	}
 	d.schemas = append(d.schemas, &sc)
 	return &sc
 }
 
+// SchemaType is document data scheme configuration of the type, no example
+// value is needed. Examples are taken from the struct tags or synthesized.
+func (d *Doc) SchemaType(t reflect.Type) *SchemaConfig {
+	sc := d.Schema(nil)
+	sc.Type = t
+	return sc
+}
+
+// SchemaOf is SchemaType of T. Ex: qdoc.SchemaOf[User](doc)
+func SchemaOf[T any](d *Doc) *SchemaConfig {
+	return d.SchemaType(reflect.TypeOf((*T)(nil)).Elem())
+}
+
+// ComponentOf is Component of T, no example value is needed
+func ComponentOf[T any](d *Doc) *SchemaConfig {
+	sc := SchemaOf[T](d)
+	sc.component = d.refTypeOf(sc.Type)
+	return sc
+}
+
+// Component is a reusable document data scheme configuration. The schema of the
+// value type is added to components/schemas and every usage of the same type
+// refers to it. Value must be a named struct or a pointer to a named struct,
+// otherwise it is documented inline same as Schema.
+func (d *Doc) Component(value interface{}) *SchemaConfig {
+	sc := d.Schema(value)
+	sc.component = d.refType(value)
+	return sc
+}
+
+// OneOf is a document data scheme which is exactly one of the value types,
+// named struct types are referred as components (see Component)
+func (d *Doc) OneOf(values ...interface{}) *SchemaConfig {
+	return d.union(schema.UnionKind_ONE_OF, values)
+}
+
+// AnyOf is a document data scheme which is one or more of the value types
+func (d *Doc) AnyOf(values ...interface{}) *SchemaConfig {
+	return d.union(schema.UnionKind_ANY_OF, values)
+}
+
+// AllOf is a document data scheme which is all of the value types combined
+func (d *Doc) AllOf(values ...interface{}) *SchemaConfig {
+	return d.union(schema.UnionKind_ALL_OF, values)
+}
+
+func (d *Doc) union(kind schema.UnionKind, values []interface{}) *SchemaConfig {
+	sc := d.Schema(nil)
+	sc.union = kind
+	sc.members = values
+	for _, value := range values {
+		d.refType(value)
+	}
+	return sc
+}
+
+// refType registers the type of the value as a referred type, it reports
+// false when the type is not a named struct or a pointer to a named struct
+func (d *Doc) refType(value interface{}) bool {
+	return d.refTypeOf(reflect.TypeOf(value))
+}
+
+func (d *Doc) refTypeOf(t reflect.Type) bool {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t != nil && t.Kind() == reflect.Struct && t.Name() != "" {
+		d.schemaOpts.RefTypes[t] = true
+		return true
+	}
+	return false
+}
+
 type SchemaConfig struct {
-	Object    interface{}
-	builder   schema.Builder
-	component bool
+	Object        interface{}
+	Type          reflect.Type // type of type only schemas, Object is not used when set
+	builder       schema.Builder
+	component     bool
+	skipped       []string
+	props         map[string]*schema.Property // built properties by name tag, reset by Compile
+	union         schema.UnionKind
+	members       []interface{}
+	discriminator *schema.Discriminator
 }
 
-func (sc *SchemaConfig) toOpenAPI() *openapi3.Schema {
+// Discriminator sets the property which tells the members of OneOf and AnyOf
+// schemas apart, mapping maps the property values to the member values. Ex:
+// doc.OneOf(CardPayment{}, WalletPayment{}).Discriminator("type", map[string]interface{}{"card": CardPayment{}})
+func (sc *SchemaConfig) Discriminator(propertyName string, mapping map[string]interface{}) *SchemaConfig {
+	sc.discriminator = schema.NewDiscriminator(propertyName, mapping)
+	return sc
+}
+
+// toOpenAPI converts the schema, properties are named by the name tag of the components
+func (sc *SchemaConfig) toOpenAPI(c *components) (*openapi3.SchemaRef, error) {
 	if sc == nil {
-		return openapi3.NewSchema()
+		return openapi3.NewSchemaRef("", openapi3.NewSchema()), nil
 	}
-	prop, err := sc.builder.GetSchema(sc.Object)
-	if err != nil {
-		return nil
+	// components and every usage of the schema share the same properties
+	prop, ok := sc.props[c.nameTag]
+	if !ok {
+		builder := &sc.builder
+		if c.nameTag != "" || sc.component {
+			opts := *sc.builder.Options
+			if c.nameTag != "" {
+				opts.NameTag = c.nameTag
+			}
+			// components are shared by every usage, nested structs are
+			// documented even when the example has none
+			opts.ExploreNilStruct = opts.ExploreNilStruct || sc.component
+			_builder := schema.NewBuilder(&opts)
+			builder = &_builder
+		}
+		var err error
+		if prop, err = sc.property(builder); err != nil {
+			return nil, err
+		}
+		if sc.props == nil {
+			sc.props = make(map[string]*schema.Property)
+		}
+		sc.props[c.nameTag] = prop
 	}
-	return propToOpenAPI(prop)
+	return c.propToSchemaRef(prop)
 }
 
-func propToOpenAPI(prop *schema.Property) *openapi3.Schema {
+// property returns the schema of the object, or the union of the members
+func (sc *SchemaConfig) property(builder *schema.Builder) (*schema.Property, error) {
+	if sc.Type != nil {
+		prop, err := builder.GetSchemaType(sc.Type)
+		sc.skipped = append(sc.skipped, builder.Skipped()...)
+		return prop, err
+	}
+	if sc.union == "" {
+		prop, err := builder.GetSchema(sc.Object)
+		sc.skipped = append(sc.skipped, builder.Skipped()...)
+		return prop, err
+	}
+	members := make([]schema.Property, 0, len(sc.members))
+	for _, member := range sc.members {
+		prop, err := builder.GetSchema(member)
+		if err != nil {
+			return nil, err
+		}
+		sc.skipped = append(sc.skipped, builder.Skipped()...)
+		if prop != nil {
+			members = append(members, *prop)
+		}
+	}
+	return schema.UnionProperty(sc.union, members, sc.discriminator), nil
+}
+
+// propToSchemaRef converts the property into a schema reference. Properties of
+// recursive types are registered as components and referred by $ref.
+func (c *components) propToSchemaRef(prop *schema.Property) (*openapi3.SchemaRef, error) {
+	if prop == nil || prop.Ref == "" {
+		sc, err := c.propToOpenAPI(prop)
+		if err != nil {
+			return nil, err
+		}
+		return openapi3.NewSchemaRef("", sc), nil
+	}
+	// properties of the inner most occurrence are not explored, the outer one defines the component
+	if prop.Properties != nil && !c.has(prop.Ref) {
+		body := *prop
+		body.Name = ""
+		body.Ref = ""
+		// metadata of the field belongs to the usage, not to the component
+		body.Description = ""
+		body.Nullable = false
+		body.ReadOnly = false
+		body.WriteOnly = false
+		body.Deprecated = false
+		body.Constraints = nil
+		// register before converting, so nested occurrences do not take over the component
+		sc := openapi3.NewSchema()
+		c.register(prop.Ref, sc)
+		_sc, err := c.propToOpenAPI(&body)
+		if err != nil {
+			return nil, err
+		}
+		*sc = *_sc
+	}
+	if !hasFieldMetadata(prop) {
+		return c.schemaRef(prop.Ref), nil
+	}
+	// siblings of $ref are ignored, references with metadata of the field are wrapped
+	sc := &openapi3.Schema{
+		AllOf:       openapi3.SchemaRefs{c.schemaRef(prop.Ref)},
+		Description: prop.Description,
+		Nullable:    prop.Nullable,
+		ReadOnly:    prop.ReadOnly,
+		WriteOnly:   prop.WriteOnly,
+		Deprecated:  prop.Deprecated,
+	}
+	for _, con := range prop.Constraints {
+		constraintToOpenAPI(sc, prop.Type, con)
+	}
+	return openapi3.NewSchemaRef("", sc), nil
+}
+
+// hasFieldMetadata reports whether the referred property has documentation of
+// its own, required constraints are documented by the parent object
+func hasFieldMetadata(prop *schema.Property) bool {
+	if prop.Description != "" || prop.Nullable || prop.ReadOnly || prop.WriteOnly || prop.Deprecated {
+		return true
+	}
+	for _, con := range prop.Constraints {
+		if con.Type != schema.ConType_REQUIRED {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *components) propToOpenAPI(prop *schema.Property) (*openapi3.Schema, error) {
 	if prop == nil {
-		return openapi3.NewSchema()
+		return openapi3.NewSchema(), nil
+	}
+
+	sc, err := c.propTypeToOpenAPI(prop)
+	if err != nil {
+		return nil, err
+	}
+	if prop.Format != "" {
+		sc.Format = prop.Format
+	}
+	if len(prop.Enum) > 0 {
+		sc.Enum = prop.Enum
+	}
+	if prop.XML != nil {
+		sc.XML = &openapi3.XML{
+			Name:      prop.XML.Name,
+			Namespace: prop.XML.Namespace,
+			Attribute: prop.XML.Attribute,
+			Wrapped:   prop.XML.Wrapped,
+		}
+	}
+	for i := range prop.OneOf {
+		ref, err := c.propToSchemaRef(&prop.OneOf[i])
+		if err != nil {
+			return nil, err
+		}
+		sc.OneOf = append(sc.OneOf, ref)
+	}
+	for i := range prop.AnyOf {
+		ref, err := c.propToSchemaRef(&prop.AnyOf[i])
+		if err != nil {
+			return nil, err
+		}
+		sc.AnyOf = append(sc.AnyOf, ref)
+	}
+	if prop.Discriminator != nil {
+		sc.Discriminator = c.discriminator(prop.Discriminator)
+	}
+	sc.Nullable = prop.Nullable
+	sc.UniqueItems = prop.UniqueItems
+	sc.Deprecated = prop.Deprecated
+	sc.ReadOnly = prop.ReadOnly
+	sc.WriteOnly = prop.WriteOnly
+	for _, con := range prop.Constraints {
+		constraintToOpenAPI(sc, prop.Type, con)
+	}
+	return sc, nil
+}
+
+// constraintToOpenAPI sets the keywords of the constraint on the schema.
+// Required constraints are handled by the parent object and one of
+// constraints are carried by Property.Enum.
+func constraintToOpenAPI(sc *openapi3.Schema, t schema.PropType, con schema.Constraint) {
+	switch con.Type {
+	case schema.ConType_MIN:
+		setMin(sc, t, con.Min, con.Exclusive)
+	case schema.ConType_MAX:
+		setMax(sc, t, con.Max, con.Exclusive)
+	case schema.ConType_BETWEEN:
+		setMin(sc, t, con.Min, con.Exclusive)
+		setMax(sc, t, con.Max, con.Exclusive)
+	case schema.ConType_FORMAT:
+		if sc.Format == "" {
+			sc.Format = con.Value
+		}
+	case schema.ConType_PATTERN:
+		sc.Pattern = con.Value
+	}
+}
+
+// setMin sets the lower bound, exclusive lengths and counts are one more
+func setMin(sc *openapi3.Schema, t schema.PropType, n float64, exclusive bool) {
+	if exclusive && t != schema.PropType_INTEGER && t != schema.PropType_NUMBER {
+		n++
+	}
+	switch t {
+	case schema.PropType_STRING:
+		sc.MinLength = uint64(n)
+	case schema.PropType_ARRAY:
+		sc.MinItems = uint64(n)
+	case schema.PropType_MAP:
+		sc.MinProps = uint64(n)
+	case schema.PropType_INTEGER, schema.PropType_NUMBER:
+		sc.Min = &n
+		sc.ExclusiveMin = exclusive
 	}
+}
 
+// setMax sets the upper bound, exclusive lengths and counts are one less
+func setMax(sc *openapi3.Schema, t schema.PropType, n float64, exclusive bool) {
+	if exclusive && t != schema.PropType_INTEGER && t != schema.PropType_NUMBER && n > 0 {
+		n--
+	}
+	u := uint64(n)
+	switch t {
+	case schema.PropType_STRING:
+		sc.MaxLength = &u
+	case schema.PropType_ARRAY:
+		sc.MaxItems = &u
+	case schema.PropType_MAP:
+		sc.MaxProps = &u
+	case schema.PropType_INTEGER, schema.PropType_NUMBER:
+		sc.Max = &n
+		sc.ExclusiveMax = exclusive
+	}
+}
+
+// propTypeToOpenAPI converts the property into a schema of the matching OpenAPI type
+func (c *components) propTypeToOpenAPI(prop *schema.Property) (*openapi3.Schema, error) {
 	switch prop.Type {
 	case schema.PropType_STRING:
 		return &openapi3.Schema{
@@ -47,59 +349,167 @@ func propToOpenAPI(prop *schema.Property) *openapi3.Schema {
 			Example:     prop.Value,
 			Title:       prop.Name,
 			Description: prop.Description,
-		}
+		}, nil
 	case schema.PropType_INTEGER:
 		return &openapi3.Schema{
 			Type:        "integer",
 			Example:     prop.Value,
 			Title:       prop.Name,
 			Description: prop.Description,
-		}
+		}, nil
 	case schema.PropType_NUMBER:
 		return &openapi3.Schema{
 			Type:        "number",
 			Example:     prop.Value,
 			Title:       prop.Name,
 			Description: prop.Description,
-		}
+		}, nil
 	case schema.PropType_BOOLEAN:
 		return &openapi3.Schema{
 			Type:        "boolean",
 			Example:     prop.Value,
 			Title:       prop.Name,
 			Description: prop.Description,
-		}
+		}, nil
 	case schema.PropType_ARRAY:
+		items := openapi3.NewSchemaRef("", openapi3.NewSchema())
+		if item := prop.ItemSchema(); item != nil {
+			var err error
+			if items, err = c.propToSchemaRef(item); err != nil {
+				return nil, err
+			}
+		}
 		return &openapi3.Schema{
-			Type: "array",
-			Items: &openapi3.SchemaRef{
-				Value: propToOpenAPI(&prop.Properties[0]),
-			},
+			Type:        "array",
+			Items:       items,
+			Example:     propExample(prop),
 			Title:       prop.Name,
 			Description: prop.Description,
-		}
+		}, nil
 	case schema.PropType_OBJECT:
 		properties := make(map[string]*openapi3.SchemaRef)
+		var required []string
 
 		for _, p := range prop.Properties {
-			properties[p.Name] = &openapi3.SchemaRef{
-				Value: propToOpenAPI(&p),
+			ref, err := c.propToSchemaRef(&p)
+			if err != nil {
+				return nil, err
+			}
+			properties[p.Name] = ref
+			if p.Required {
+				required = append(required, p.Name)
 			}
 		}
-		return &openapi3.Schema{
+		sc := &openapi3.Schema{
 			Type:        "object",
 			Properties:  properties,
+			Required:    required,
+			Example:     propExample(prop),
+			Title:       prop.Name,
+			Description: prop.Description,
+		}
+		if len(prop.AllOf) == 0 {
+			return sc, nil
+		}
+		// embedded structs are referred, own fields are the last element of allOf
+		all := &openapi3.Schema{
+			Title:       prop.Name,
+			Description: prop.Description,
+		}
+		for i := range prop.AllOf {
+			ref, err := c.propToSchemaRef(&prop.AllOf[i])
+			if err != nil {
+				return nil, err
+			}
+			all.AllOf = append(all.AllOf, ref)
+		}
+		// all of unions have no fields of their own
+		if len(prop.Properties) > 0 {
+			sc.Title = ""
+			sc.Description = ""
+			all.AllOf = append(all.AllOf, openapi3.NewSchemaRef("", sc))
+		}
+		return all, nil
+	case schema.PropType_MAP:
+		sc := &openapi3.Schema{
+			Type:        "object",
+			Example:     propExample(prop),
 			Title:       prop.Name,
 			Description: prop.Description,
 		}
+		if prop.Elem != nil {
+			ref, err := c.propToSchemaRef(prop.Elem)
+			if err != nil {
+				return nil, err
+			}
+			sc.AdditionalProperties = ref
+		} else {
+			sc.WithAnyAdditionalProperties()
+		}
+		if prop.Key != nil {
+			key, err := c.propToOpenAPI(prop.Key)
+			if err != nil {
+				return nil, err
+			}
+			// OpenAPI object keys are always strings, keep the go key type as an extension
+			sc.Extensions = map[string]interface{}{
+				"x-key-type": key.Type,
+			}
+		}
+		return sc, nil
+	case schema.PropType_ANY:
+		return &openapi3.Schema{
+			Example:     prop.Value,
+			Title:       prop.Name,
+			Description: prop.Description,
+		}, nil
 	case schema.PropType_STRUCT:
 		return &openapi3.Schema{
 			Type:        "string",
 			Example:     "<Empty data sctructure>",
 			Title:       "<Empty data sctructure>",
 			Description: "<Empty data sctructure>",
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown property type %q of %q", prop.Type, prop.Name)
+	}
+}
+
+// propExample assembles an example value from the property and its children.
+// Objects and arrays without any example value have no example.
+func propExample(prop *schema.Property) interface{} {
+	if prop.Value != nil {
+		return prop.Value
+	}
+	switch prop.Type {
+	case schema.PropType_OBJECT, schema.PropType_MAP:
+		example := make(map[string]interface{})
+		found := false
+		for i := range prop.Properties {
+			value := propExample(&prop.Properties[i])
+			// files have no example
+			if value == nil && prop.Properties[i].Format == "binary" {
+				continue
+			}
+			example[prop.Properties[i].Name] = value
+			found = found || value != nil
+		}
+		if !found {
+			return nil
 		}
+		return example
+	case schema.PropType_ARRAY:
+		example := make([]interface{}, 0)
+		for i := range prop.Properties {
+			if value := propExample(&prop.Properties[i]); value != nil {
+				example = append(example, value)
+			}
+		}
+		if len(example) == 0 {
+			return nil
+		}
+		return example
 	default:
-		panic("unknown type")
+		return nil
 	}
 }
@@ -1,7 +1,6 @@
 package qdoc
 
 import (
-	"fmt"
 	"github.com/pickme-lk/quick-doc/ui"
 	"net/http"
 )
@@ -26,12 +25,9 @@ func serveUi(html string) func(http.ResponseWriter, *http.Request) {
 	}
 }
 
+// serveUiDynamic serves the web viewer of the theme query parameter, htmlMap must
+// contain the default theme (see Doc.compileUi)
 func serveUiDynamic(defaultTheme ui.Theme, htmlMap map[ui.Theme]string) func(http.ResponseWriter, *http.Request) {
-
-	if _, ok := htmlMap[defaultTheme]; !ok {
-		panic(fmt.Sprintf("Default theme %s not found", defaultTheme))
-	}
-
 	defaultHTML := htmlMap[defaultTheme]
 
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -60,34 +56,10 @@ func (cd *CompiledDoc) ServeMux() *http.ServeMux {
 
 	if cd.config.UiConfig.Enabled {
 		if cd.config.UiConfig.ThemeByQuery {
-			var htmlMap = make(map[ui.Theme]string)
-			for _, theme := range []ui.Theme{ui.SWAGGER_UI, ui.RAPI_DOC} {
-				html, err := ui.HTML(ui.Config{
-					Theme:   theme,
-					Title:   cd.config.Title,
-					SpecUrl: cd.config.SpecPath,
-					LogoUrl: cd.config.UiConfig.LogoUrl,
-				})
-				if err != nil {
-					panic(err)
-				}
-				htmlMap[theme] = html
-			}
-
-			s.HandleFunc(cd.config.UiConfig.Path, serveUiDynamic(cd.config.UiConfig.DefaultTheme, htmlMap))
+			s.HandleFunc(cd.config.UiConfig.Path, serveUiDynamic(cd.config.UiConfig.DefaultTheme, cd.uiHTML))
 		} else {
-			html, err := ui.HTML(ui.Config{
-				Theme:   cd.config.UiConfig.DefaultTheme,
-				Title:   cd.config.Title,
-				SpecUrl: cd.config.SpecPath,
-				LogoUrl: cd.config.UiConfig.LogoUrl,
-			})
-			if err != nil {
-				panic(err)
-			}
-			s.HandleFunc(cd.config.UiConfig.Path, serveUi(html))
+			s.HandleFunc(cd.config.UiConfig.Path, serveUi(cd.uiHTML[cd.config.UiConfig.DefaultTheme]))
 		}
-
 	}
 
 	return s
@@ -0,0 +1,158 @@
+package schema
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Cache keeps type level metadata which is shared by builders, struct field
+// details and the schemas of types which are inspected without a value or
+// with the zero value. It is safe for concurrent use. Options must not change
+// while the cache is in use, Reset the cache after changing them.
+type Cache struct {
+	mu      sync.RWMutex
+	fields  map[cacheKey][]fieldInfo
+	schemas map[cacheKey]*Property
+}
+
+type cacheKey struct {
+	t          reflect.Type
+	nameTag    string
+	zero       bool // inspected with the zero value
+	exploreNil bool // nil structs are explored, see Builder.exploreNil
+}
+
+// fieldInfo struct field details which do not depend on the field value
+type fieldInfo struct {
+	ignored     bool
+	embedded    reflect.Type // promoted embedded struct
+	name        string
+	tagged      bool
+	required    bool
+	constraints []Constraint
+	tags        []tagItem
+}
+
+func NewCache() *Cache {
+	c := &Cache{}
+	c.Reset()
+	return c
+}
+
+// Reset removes every cached entry
+func (c *Cache) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fields = make(map[cacheKey][]fieldInfo)
+	c.schemas = make(map[cacheKey]*Property)
+}
+
+func (c *Cache) getSchema(key cacheKey) (*Property, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	prop, ok := c.schemas[key]
+	if !ok {
+		return nil, false
+	}
+	return prop.clone(), true
+}
+
+func (c *Cache) putSchema(key cacheKey, prop *Property) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.schemas[key] = prop.clone()
+}
+
+// structFields returns the details of the struct fields
+func (b *Builder) structFields(t reflect.Type) []fieldInfo {
+	c := b.Options.Cache
+	if c == nil {
+		return b.fieldInfos(t)
+	}
+	key := cacheKey{t: t, nameTag: b.nameTag()}
+	c.mu.RLock()
+	fields, ok := c.fields[key]
+	c.mu.RUnlock()
+	if ok {
+		return fields
+	}
+	fields = b.fieldInfos(t)
+	c.mu.Lock()
+	c.fields[key] = fields
+	c.mu.Unlock()
+	return fields
+}
+
+func (b *Builder) fieldInfos(t reflect.Type) []fieldInfo {
+	fields := make([]fieldInfo, t.NumField())
+	for i := range fields {
+		sf := t.Field(i)
+		if b.ignoreField(sf) {
+			fields[i].ignored = true
+			continue
+		}
+		if et, ok := b.embeddedStruct(sf); ok {
+			fields[i].embedded = et
+			continue
+		}
+		fields[i] = fieldInfo{
+			name:        b.structFieldName(sf),
+			tagged:      b.tagName(sf) != "",
+			required:    b.requiredField(sf),
+			constraints: b.fieldConstraints(sf),
+			tags:        b.fieldTags(sf),
+		}
+	}
+	return fields
+}
+
+// inspectCached returns the schema of a type which is inspected without a
+// value or with the zero value from the cache. Schemas which depend on the
+// types being inspected, recursive types and skipped values, are not cached.
+func (b *Builder) inspectCached(t reflect.Type, v reflect.Value) (*Property, error) {
+	c := b.Options.Cache
+	key := cacheKey{t: t, nameTag: b.nameTag(), zero: v.IsValid(), exploreNil: b.exploreNil()}
+	if prop, ok := c.getSchema(key); ok {
+		return prop, nil
+	}
+	cycles, skipped := b.cycles, len(b.skipped)
+	prop, err := b.inspectType(t, v)
+	if err == nil && prop != nil && cycles == b.cycles && skipped == len(b.skipped) {
+		c.putSchema(key, prop)
+	}
+	return prop, err
+}
+
+// clone returns a deep copy of the property, values and enums are shared
+func (p *Property) clone() *Property {
+	if p == nil {
+		return nil
+	}
+	c := *p
+	c.Properties = cloneAll(p.Properties)
+	c.AllOf = cloneAll(p.AllOf)
+	c.OneOf = cloneAll(p.OneOf)
+	c.AnyOf = cloneAll(p.AnyOf)
+	c.Key = p.Key.clone()
+	c.Elem = p.Elem.clone()
+	if p.Constraints != nil {
+		c.Constraints = make([]Constraint, len(p.Constraints))
+		copy(c.Constraints, p.Constraints)
+	}
+	if p.XML != nil {
+		x := *p.XML
+		c.XML = &x
+	}
+	return &c
+}
+
+func cloneAll(props []Property) []Property {
+	if props == nil {
+		return nil
+	}
+	c := make([]Property, len(props))
+	for i := range props {
+		c[i] = *props[i].clone()
+	}
+	return c
+}
@@ -1,5 +1,11 @@
 package schema
 
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
 // ConstraintType Constraint types
 type ConstraintType string
 
@@ -8,10 +14,124 @@ const (
 	ConType_MAX      ConstraintType = "MAX"
 	ConType_MIN      ConstraintType = "MIN"
 	ConType_BETWEEN  ConstraintType = "BETWEEN"
+	ConType_ONE_OF   ConstraintType = "ONE_OF"
+	ConType_FORMAT   ConstraintType = "FORMAT"
+	ConType_PATTERN  ConstraintType = "PATTERN"
+	ConType_UNIQUE   ConstraintType = "UNIQUE" // array items are unique
 )
 
+// Constraint limits of a property value. Min and Max are lengths for strings,
+// item counts for arrays and property counts for maps.
 type Constraint struct {
-	Type ConstraintType `json:"type"`
-	Min  int            `json:"min,omitempty"`
-	Max  int            `json:"max,omitempty"`
+	Type      ConstraintType `json:"type"`
+	Min       float64        `json:"min,omitempty"`
+	Max       float64        `json:"max,omitempty"`
+	Exclusive bool           `json:"exclusive,omitempty"` // Min and Max are exclusive
+	Values    []string       `json:"values,omitempty"`    // allowed values of ConType_ONE_OF
+	Value     string         `json:"value,omitempty"`     // format or pattern
+}
+
+// DefaultConstraintTags struct tags which hold go-playground/validator rules
+var DefaultConstraintTags = []string{"validate", "binding"}
+
+// validator rules which are documented as formats
+var constraintFormats = map[string]string{
+	"email":    "email",
+	"url":      "uri",
+	"uri":      "uri",
+	"uuid":     "uuid",
+	"uuid4":    "uuid",
+	"ipv4":     "ipv4",
+	"ipv6":     "ipv6",
+	"hostname": "hostname",
+	"datetime": "date-time",
+}
+
+// validator rules which are documented as patterns
+var constraintPatterns = map[string]string{
+	"alpha":    "^[a-zA-Z]+$",
+	"alphanum": "^[a-zA-Z0-9]+$",
+	"numeric":  "^[-+]?[0-9]+(?:\\.[0-9]+)?$",
+	"number":   "^[0-9]+$",
+	"hexcolor": "^#(?:[0-9a-fA-F]{3}|[0-9a-fA-F]{6})$",
+}
+
+// constraintTags returns the struct tags which hold validation rules
+func (b *Builder) constraintTags() []string {
+	if b.Options.ConstraintTags == nil {
+		return DefaultConstraintTags
+	}
+	return b.Options.ConstraintTags
+}
+
+// fieldConstraints returns the validation rules of the struct field
+func (b *Builder) fieldConstraints(sf reflect.StructField) []Constraint {
+	var constraints []Constraint
+	for _, key := range b.constraintTags() {
+		if tag, ok := sf.Tag.Lookup(key); ok {
+			constraints = append(constraints, ParseConstraints(tag)...)
+		}
+	}
+	return constraints
+}
+
+// applyConstraints sets the validation rules of the struct field on the property
+func (b *Builder) applyConstraints(prop *Property, constraints []Constraint) {
+	for _, c := range constraints {
+		switch c.Type {
+		case ConType_REQUIRED:
+			prop.Required = true
+		case ConType_UNIQUE:
+			prop.UniqueItems = true
+		case ConType_ONE_OF:
+			prop.Enum = make([]interface{}, 0)
+			for _, v := range c.Values {
+				prop.Enum = append(prop.Enum, parseValue(prop.Type, v))
+			}
+		}
+		prop.WithConstraint(c)
+	}
+}
+
+// ParseConstraints converts go-playground/validator rules into constraints.
+// Example: "required,min=3,max=20,oneof=a b". Rules after dive belong to the
+// elements and unknown rules are skipped.
+func ParseConstraints(tag string) []Constraint {
+	constraints := make([]Constraint, 0)
+	for _, rule := range strings.Split(tag, ",") {
+		name, param := strings.TrimSpace(rule), ""
+		if i := strings.Index(name, "="); i >= 0 {
+			name, param = name[:i], name[i+1:]
+		}
+		if name == "dive" {
+			break
+		}
+		switch name {
+		case "required":
+			constraints = append(constraints, Constraint{Type: ConType_REQUIRED})
+		case "min", "gte", "gt":
+			if n, err := strconv.ParseFloat(param, 64); err == nil {
+				constraints = append(constraints, Constraint{Type: ConType_MIN, Min: n, Exclusive: name == "gt"})
+			}
+		case "max", "lte", "lt":
+			if n, err := strconv.ParseFloat(param, 64); err == nil {
+				constraints = append(constraints, Constraint{Type: ConType_MAX, Max: n, Exclusive: name == "lt"})
+			}
+		case "len":
+			if n, err := strconv.ParseFloat(param, 64); err == nil {
+				constraints = append(constraints, Constraint{Type: ConType_BETWEEN, Min: n, Max: n})
+			}
+		case "unique":
+			constraints = append(constraints, Constraint{Type: ConType_UNIQUE})
+		case "oneof":
+			constraints = append(constraints, Constraint{Type: ConType_ONE_OF, Values: strings.Fields(param)})
+		default:
+			if format, ok := constraintFormats[name]; ok {
+				constraints = append(constraints, Constraint{Type: ConType_FORMAT, Value: format})
+			} else if pattern, ok := constraintPatterns[name]; ok {
+				constraints = append(constraints, Constraint{Type: ConType_PATTERN, Value: pattern})
+			}
+		}
+	}
+	return constraints
 }
@@ -0,0 +1,73 @@
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// UnsupportedTypeError is returned when a type can not be documented
+type UnsupportedTypeError struct {
+	Type reflect.Type
+	Path string // field path of the value. Ex: OptionGetResponse.payload.data.skus
+}
+
+func (e *UnsupportedTypeError) Error() string {
+	return fmt.Sprintf("schema: unsupported type %s (%s) at %s", e.Type, e.Type.Kind(), e.Path)
+}
+
+// FieldError is returned when a field value can not be documented.
+// Ex: MarshalJSON of the value returns an error
+type FieldError struct {
+	Path string
+	Err  error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("schema: %s: %s", e.Path, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// pushPath adds a segment to the field path, segments of elements start with "["
+func (b *Builder) pushPath(segment string) {
+	b.path = append(b.path, segment)
+}
+
+func (b *Builder) popPath() {
+	b.path = b.path[:len(b.path)-1]
+}
+
+// fieldPath returns the field path of the value which is being inspected
+func (b *Builder) fieldPath() string {
+	var sb strings.Builder
+	for i, segment := range b.path {
+		if i > 0 && !strings.HasPrefix(segment, "[") {
+			sb.WriteString(".")
+		}
+		sb.WriteString(segment)
+	}
+	return sb.String()
+}
+
+func (b *Builder) unsupportedType(t reflect.Type) error {
+	return &UnsupportedTypeError{
+		Type: t,
+		Path: b.fieldPath(),
+	}
+}
+
+func (b *Builder) fieldError(err error) error {
+	if _, ok := err.(*FieldError); ok {
+		return err
+	}
+	if _, ok := err.(*UnsupportedTypeError); ok {
+		return err
+	}
+	return &FieldError{
+		Path: b.fieldPath(),
+		Err:  err,
+	}
+}
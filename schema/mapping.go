@@ -0,0 +1,167 @@
+package schema
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"mime/multipart"
+	"net"
+	"reflect"
+	"time"
+)
+
+// TypeMapping describes values of a go type as a single property, instead of
+// exploring the type with reflection
+type TypeMapping struct {
+	Type     PropType
+	Format   string
+	Nullable bool
+	// Example returns the example of a valid value, default: the value itself
+	Example func(v reflect.Value) interface{}
+}
+
+// DefaultTypeMappings built-in type mappings, Options.TypeMappings take precedence
+var DefaultTypeMappings = map[reflect.Type]TypeMapping{
+	reflect.TypeOf(time.Time{}): {
+		Type:    PropType_STRING,
+		Format:  "date-time",
+		Example: timeExample,
+	},
+	reflect.TypeOf(time.Duration(0)): {
+		Type:   PropType_INTEGER,
+		Format: "int64",
+	},
+	reflect.TypeOf(json.RawMessage{}): {
+		Type: PropType_ANY,
+		Example: func(v reflect.Value) interface{} {
+			if v.Len() == 0 {
+				return nil
+			}
+			return json.RawMessage(v.Bytes())
+		},
+	},
+	reflect.TypeOf(json.Number("")): {
+		Type: PropType_NUMBER,
+		Example: func(v reflect.Value) interface{} {
+			return json.Number(v.String())
+		},
+	},
+	reflect.TypeOf(big.Int{}): {
+		Type:    PropType_INTEGER,
+		Example: bigIntExample,
+	},
+	reflect.TypeOf(net.IP{}): {
+		Type: PropType_STRING,
+		Example: func(v reflect.Value) interface{} {
+			if v.Len() == 0 {
+				return nil
+			}
+			return v.Interface().(net.IP).String()
+		},
+	},
+	reflect.TypeOf(multipart.FileHeader{}): FileMapping,
+
+	reflect.TypeOf(sql.NullString{}):  {Type: PropType_STRING, Nullable: true, Example: nullExample},
+	reflect.TypeOf(sql.NullInt64{}):   {Type: PropType_INTEGER, Format: "int64", Nullable: true, Example: nullExample},
+	reflect.TypeOf(sql.NullInt32{}):   {Type: PropType_INTEGER, Format: "int32", Nullable: true, Example: nullExample},
+	reflect.TypeOf(sql.NullInt16{}):   {Type: PropType_INTEGER, Nullable: true, Example: nullExample},
+	reflect.TypeOf(sql.NullByte{}):    {Type: PropType_INTEGER, Nullable: true, Example: nullExample},
+	reflect.TypeOf(sql.NullFloat64{}): {Type: PropType_NUMBER, Format: "double", Nullable: true, Example: nullExample},
+	reflect.TypeOf(sql.NullBool{}):    {Type: PropType_BOOLEAN, Nullable: true, Example: nullExample},
+	reflect.TypeOf(sql.NullTime{}):    {Type: PropType_STRING, Format: "date-time", Nullable: true, Example: nullExample},
+}
+
+// FileMapping files of multipart bodies are binary strings, files have no example
+var FileMapping = TypeMapping{
+	Type:   PropType_STRING,
+	Format: "binary",
+	Example: func(v reflect.Value) interface{} {
+		return nil
+	},
+}
+
+// byteSliceMapping byte slices are encoded as base64 strings
+var byteSliceMapping = TypeMapping{
+	Type:   PropType_STRING,
+	Format: "byte",
+	Example: func(v reflect.Value) interface{} {
+		return base64.StdEncoding.EncodeToString(v.Bytes())
+	},
+}
+
+// uuidMapping uuid.UUID style [16]byte arrays
+var uuidMapping = TypeMapping{
+	Type:   PropType_STRING,
+	Format: "uuid",
+	Example: func(v reflect.Value) interface{} {
+		b := make([]byte, 16)
+		reflect.Copy(reflect.ValueOf(b), v)
+		return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+	},
+}
+
+// WithTypeMapping registers the mapping of the go type
+func (o *Options) WithTypeMapping(t reflect.Type, m TypeMapping) *Options {
+	if o.TypeMappings == nil {
+		o.TypeMappings = make(map[reflect.Type]TypeMapping)
+	}
+	o.TypeMappings[t] = m
+	return o
+}
+
+// typeMapping returns the mapping of the go type if there is any
+func (b *Builder) typeMapping(t reflect.Type) (TypeMapping, bool) {
+	if m, ok := b.Options.TypeMappings[t]; ok {
+		return m, true
+	}
+	if m, ok := DefaultTypeMappings[t]; ok {
+		return m, true
+	}
+	if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+		return byteSliceMapping, true
+	}
+	if t.Kind() == reflect.Array && t.Len() == 16 && t.Elem().Kind() == reflect.Uint8 && t.Name() == "UUID" {
+		return uuidMapping, true
+	}
+	return TypeMapping{}, false
+}
+
+// mappedProperty returns the property of a mapped type
+func (b *Builder) mappedProperty(m TypeMapping, v reflect.Value) *Property {
+	prop := &Property{
+		Type:     m.Type,
+		Format:   m.Format,
+		Nullable: m.Nullable,
+	}
+	// values of unexported fields can not be passed to Example, they have no example
+	switch {
+	case m.Example == nil:
+		prop.Value = b.value(v)
+	case v.IsValid() && v.CanInterface():
+		prop.Value = m.Example(v)
+	}
+	return prop
+}
+
+func timeExample(v reflect.Value) interface{} {
+	return v.Interface().(time.Time).Format(time.RFC3339)
+}
+
+func bigIntExample(v reflect.Value) interface{} {
+	i := v.Interface().(big.Int)
+	return json.Number(i.String())
+}
+
+// nullExample example of sql.Null* types, the value is the first field
+func nullExample(v reflect.Value) interface{} {
+	if !v.FieldByName("Valid").Bool() {
+		return nil
+	}
+	value := v.Field(0)
+	if t, ok := value.Interface().(time.Time); ok {
+		return t.Format(time.RFC3339)
+	}
+	return value.Interface()
+}
@@ -0,0 +1,113 @@
+package schema
+
+// DefaultMaxItems number of slice elements which are inspected by default
+const DefaultMaxItems = 100
+
+// maxItems returns the number of slice elements which are inspected
+func (b *Builder) maxItems() int {
+	if b.Options.MaxItems <= 0 {
+		return DefaultMaxItems
+	}
+	return b.Options.MaxItems
+}
+
+// ItemSchema returns the schema of the array items, the merged schema of the
+// elements or the first element. It is nil when the items are unknown.
+func (p *Property) ItemSchema() *Property {
+	if p.Elem != nil {
+		return p.Elem
+	}
+	if len(p.Properties) > 0 {
+		return &p.Properties[0]
+	}
+	return nil
+}
+
+// mergeProperties merges the schemas of slice elements. Objects give the
+// union of their fields, elements of different types give one of them.
+func mergeProperties(props []Property) *Property {
+	var keys []string
+	groups := make(map[string][]Property)
+	for _, p := range props {
+		key := string(p.Type) + p.Ref
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], p)
+	}
+	if len(keys) == 1 {
+		return mergeSameType(groups[keys[0]])
+	}
+	members := make([]Property, 0, len(keys))
+	for _, key := range keys {
+		members = append(members, *mergeSameType(groups[key]))
+	}
+	return UnionProperty(UnionKind_ONE_OF, members, nil)
+}
+
+// mergeSameType merges properties of the same type, the first one gives the metadata
+func mergeSameType(props []Property) *Property {
+	merged := props[0]
+	merged.Name = ""
+	merged.Required = false
+	switch merged.Type {
+	case PropType_OBJECT:
+		merged.Properties = mergeFields(props)
+	case PropType_ARRAY:
+		var items []Property
+		for _, p := range props {
+			if item := p.ItemSchema(); item != nil {
+				items = append(items, *item)
+			}
+		}
+		if len(items) > 0 {
+			merged.Elem = mergeProperties(items)
+		}
+	case PropType_MAP:
+		var elems []Property
+		for _, p := range props {
+			if p.Elem != nil {
+				elems = append(elems, *p.Elem)
+			}
+		}
+		if len(elems) > 0 {
+			merged.Elem = mergeProperties(elems)
+		}
+	}
+	return &merged
+}
+
+// mergeFields returns the union of the object fields in the order of their
+// first appearance, fields are required when every object requires them
+func mergeFields(objects []Property) []Property {
+	explored := false
+	for _, o := range objects {
+		explored = explored || o.Properties != nil
+	}
+	// cycle points of recursive types have no fields
+	if !explored {
+		return nil
+	}
+	var names []string
+	byName := make(map[string][]Property)
+	for _, o := range objects {
+		for _, f := range o.Properties {
+			if _, ok := byName[f.Name]; !ok {
+				names = append(names, f.Name)
+			}
+			byName[f.Name] = append(byName[f.Name], f)
+		}
+	}
+	fields := make([]Property, 0, len(names))
+	for _, name := range names {
+		same := byName[name]
+		field := mergeProperties(same)
+		field.Name = name
+		field.Required = len(same) == len(objects)
+		for _, f := range same {
+			field.Required = field.Required && f.Required
+		}
+		fields = append(fields, *field)
+	}
+	return fields
+}
@@ -0,0 +1,111 @@
+package schema
+
+import (
+	"encoding/xml"
+	"reflect"
+	"strings"
+)
+
+// DefaultNameTag struct tag which governs property names by default
+const DefaultNameTag = "json"
+
+var xmlNameType = reflect.TypeOf(xml.Name{})
+
+// tagNamings names the untagged fields as the encoder of the name tag does,
+// Ex: yaml lowercases field names
+var tagNamings = map[string]func(string) string{
+	"yaml": strings.ToLower,
+}
+
+// XML OpenAPI xml object, only set when the builder documents xml (Options.NameTag xml)
+type XML struct {
+	Name      string `json:"name,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Attribute bool   `json:"attribute,omitempty"`
+	Wrapped   bool   `json:"wrapped,omitempty"`
+}
+
+func (b *Builder) nameTag() string {
+	if b.Options.NameTag == "" {
+		return DefaultNameTag
+	}
+	return b.Options.NameTag
+}
+
+// tagName returns the name given by the name tag of the struct field. Xml
+// names are given without the namespace, nested names give the outer one.
+func (b *Builder) tagName(sf reflect.StructField) string {
+	name := strings.Split(sf.Tag.Get(b.nameTag()), ",")[0]
+	if b.nameTag() != "xml" {
+		return name
+	}
+	_, name = xmlNamespace(name)
+	return strings.Split(name, ">")[0]
+}
+
+// tagOptions returns the options of the name tag of the struct field. Ex: omitempty
+func (b *Builder) tagOptions(sf reflect.StructField) []string {
+	return strings.Split(sf.Tag.Get(b.nameTag()), ",")[1:]
+}
+
+// xmlNameField reports whether the struct field names the xml element of the struct
+func (b *Builder) xmlNameField(sf reflect.StructField) bool {
+	return b.nameTag() == "xml" && sf.Name == "XMLName" && sf.Type == xmlNameType
+}
+
+// xmlStruct returns the xml object of a struct which has an XMLName field
+func (b *Builder) xmlStruct(t reflect.Type) *XML {
+	if b.nameTag() != "xml" {
+		return nil
+	}
+	sf, ok := t.FieldByName("XMLName")
+	if !ok || sf.Type != xmlNameType {
+		return nil
+	}
+	ns, name := xmlNamespace(strings.Split(sf.Tag.Get("xml"), ",")[0])
+	if name == "" && ns == "" {
+		return nil
+	}
+	return &XML{
+		Name:      name,
+		Namespace: ns,
+	}
+}
+
+// applyXml sets the xml object of the struct field property. Nested names of
+// slices wrap the items, Ex: `xml:"items>item"`
+func (b *Builder) applyXml(prop *Property, sf reflect.StructField) {
+	if b.nameTag() != "xml" {
+		return
+	}
+	tag := strings.Split(sf.Tag.Get("xml"), ",")
+	ns, name := xmlNamespace(tag[0])
+	x := &XML{
+		Namespace: ns,
+	}
+	for _, opt := range tag[1:] {
+		if opt == "attr" {
+			x.Attribute = true
+		}
+	}
+	if path := strings.Split(name, ">"); len(path) > 1 && prop.Type == PropType_ARRAY {
+		x.Wrapped = true
+		for i := range prop.Properties {
+			prop.Properties[i].XML = &XML{Name: path[len(path)-1]}
+		}
+		if prop.Elem != nil {
+			prop.Elem.XML = &XML{Name: path[len(path)-1]}
+		}
+	}
+	if *x != (XML{}) {
+		prop.XML = x
+	}
+}
+
+// xmlNamespace splits an xml name given as "namespace name"
+func xmlNamespace(name string) (string, string) {
+	if i := strings.LastIndex(name, " "); i >= 0 {
+		return name[:i], name[i+1:]
+	}
+	return "", name
+}
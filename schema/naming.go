@@ -0,0 +1,81 @@
+package schema
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NamingStrategy converts a struct field name into a property name. It is
+// applied only to the fields without an explicit name tag.
+type NamingStrategy func(name string) string
+
+var (
+	// CamelCase UserID -> userId
+	CamelCase NamingStrategy = func(name string) string {
+		words := splitWords(name)
+		for i, w := range words {
+			if i == 0 {
+				words[i] = strings.ToLower(w)
+			} else {
+				words[i] = title(w)
+			}
+		}
+		return strings.Join(words, "")
+	}
+	// PascalCase userID -> UserId
+	PascalCase NamingStrategy = func(name string) string {
+		words := splitWords(name)
+		for i, w := range words {
+			words[i] = title(w)
+		}
+		return strings.Join(words, "")
+	}
+	// SnakeCase UserID -> user_id
+	SnakeCase NamingStrategy = func(name string) string {
+		return strings.ToLower(strings.Join(splitWords(name), "_"))
+	}
+	// KebabCase UserID -> user-id
+	KebabCase NamingStrategy = func(name string) string {
+		return strings.ToLower(strings.Join(splitWords(name), "-"))
+	}
+)
+
+// splitWords splits a go identifier into words, upper case runs are kept
+// together as acronyms. Ex: HTTPServerID -> HTTP, Server, ID
+func splitWords(name string) []string {
+	var words []string
+	runes := []rune(name)
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		prev, cur := runes[i-1], runes[i]
+		switch {
+		case cur == '_' || cur == '-':
+			// separators end the word and are dropped
+		case unicode.IsUpper(cur) && (unicode.IsLower(prev) || unicode.IsDigit(prev)):
+			// userId -> user, Id
+		case unicode.IsUpper(prev) && unicode.IsUpper(cur) && i+1 < len(runes) && unicode.IsLower(runes[i+1]):
+			// HTTPServer -> HTTP, Server
+		default:
+			continue
+		}
+		if start < i {
+			words = append(words, string(runes[start:i]))
+		}
+		start = i
+		if cur == '_' || cur == '-' {
+			start = i + 1
+		}
+	}
+	if start < len(runes) {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
+
+func title(word string) string {
+	runes := []rune(strings.ToLower(word))
+	if len(runes) > 0 {
+		runes[0] = unicode.ToUpper(runes[0])
+	}
+	return string(runes)
+}
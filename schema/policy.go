@@ -0,0 +1,71 @@
+package schema
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// KindPolicy describes how values of kinds which have no JSON representation,
+// and uintptr values, are documented
+type KindPolicy string
+
+const (
+	KindPolicy_SKIP    KindPolicy = "SKIP"    // leave the value out, see Builder.Skipped
+	KindPolicy_STRING  KindPolicy = "STRING"  // document as an opaque string
+	KindPolicy_INTEGER KindPolicy = "INTEGER" // document as an integer, Uintptr only
+	KindPolicy_ERROR   KindPolicy = "ERROR"   // return UnsupportedTypeError
+)
+
+// DefaultKindPolicies built-in kind policies, Options.KindPolicies take precedence
+var DefaultKindPolicies = map[reflect.Kind]KindPolicy{
+	reflect.Chan:          KindPolicy_SKIP,
+	reflect.Func:          KindPolicy_SKIP,
+	reflect.UnsafePointer: KindPolicy_SKIP,
+	reflect.Complex64:     KindPolicy_STRING,
+	reflect.Complex128:    KindPolicy_STRING,
+	reflect.Uintptr:       KindPolicy_INTEGER,
+}
+
+// kindPolicy returns the policy of the kind, kinds without a policy are errors
+func (b *Builder) kindPolicy(k reflect.Kind) KindPolicy {
+	if p, ok := b.Options.KindPolicies[k]; ok {
+		return p
+	}
+	if p, ok := DefaultKindPolicies[k]; ok {
+		return p
+	}
+	return KindPolicy_ERROR
+}
+
+// inspectByPolicy documents values of kinds which have no JSON representation
+func (b *Builder) inspectByPolicy(t reflect.Type, v reflect.Value) (*Property, error) {
+	switch b.kindPolicy(t.Kind()) {
+	case KindPolicy_SKIP:
+		b.skipped = append(b.skipped, fmt.Sprintf("%s (%s)", b.fieldPath(), t))
+		return nil, nil
+	case KindPolicy_STRING:
+		prop := &Property{
+			Type: PropType_STRING,
+		}
+		if v.IsValid() {
+			prop.Value = b.valueString(v)
+		}
+		return prop, nil
+	case KindPolicy_INTEGER:
+		if t.Kind() != reflect.Uintptr {
+			return nil, b.unsupportedType(t)
+		}
+		return &Property{
+			Type:  PropType_INTEGER,
+			Value: b.value(v),
+		}, nil
+	default:
+		return nil, b.unsupportedType(t)
+	}
+}
+
+// Skipped returns the field paths of the values which were left out by
+// KindPolicy_SKIP during the last GetSchema call
+func (b *Builder) Skipped() []string {
+	return b.skipped
+}
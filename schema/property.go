@@ -1,7 +1,6 @@
 package schema
 
 import (
-	"fmt"
 	"reflect"
 )
 
@@ -17,39 +16,64 @@ const (
 	PropType_ARRAY   PropType = "ARRAY"
 	PropType_MAP     PropType = "MAP"
 	PropType_STRUCT  PropType = "STRUCT"
+	PropType_ANY     PropType = "ANY"
 )
 
 // GetPropType returns corresponding PropType of the given reflect.Type
-func GetPropType(t reflect.Type) PropType {
+func GetPropType(t reflect.Type) (PropType, error) {
 	switch t.Kind() {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		return PropType_INTEGER
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		return PropType_INTEGER
+		return PropType_INTEGER, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return PropType_INTEGER, nil
 	case reflect.Float32, reflect.Float64:
-		return PropType_NUMBER
+		return PropType_NUMBER, nil
 	case reflect.Bool:
-		return PropType_BOOLEAN
+		return PropType_BOOLEAN, nil
 	case reflect.String:
-		return PropType_STRING
+		return PropType_STRING, nil
 	case reflect.Struct:
-		return PropType_OBJECT
+		return PropType_OBJECT, nil
 	case reflect.Slice, reflect.Array:
-		return PropType_ARRAY
+		return PropType_ARRAY, nil
 	case reflect.Map:
-		return PropType_MAP
+		return PropType_MAP, nil
 	default:
-		panic(fmt.Sprintf("unsupported type: %s", t.Kind()))
+		return "", &UnsupportedTypeError{Type: t, Path: t.String()}
 	}
 }
 
+// TypeRef returns the package qualified name which identifies a named type
+func TypeRef(t reflect.Type) string {
+	if t.PkgPath() == "" {
+		return t.Name()
+	}
+	return t.PkgPath() + "." + t.Name()
+}
+
 type Property struct {
-	Type        PropType     `json:"type"`
-	Name        string       `json:"name"`
-	Description string       `json:"description"`
-	Value       string       `json:"value"`
-	Properties  []Property   `json:"properties"`
-	Constraints []Constraint `json:"constraints"`
+	Type          PropType       `json:"type"`
+	Name          string         `json:"name"`
+	Description   string         `json:"description"`
+	Value         interface{}    `json:"value"` // typed example, nil when there is no value
+	Properties    []Property     `json:"properties"`
+	Constraints   []Constraint   `json:"constraints"`
+	Key           *Property      `json:"key,omitempty"`  // map key type, only set for non string keys
+	Elem          *Property      `json:"elem,omitempty"` // map value type, merged schema of array elements
+	Ref           string         `json:"ref,omitempty"`  // type reference of recursive and referred types, see TypeRef
+	Format        string         `json:"format,omitempty"`
+	Enum          []interface{}  `json:"enum,omitempty"`
+	Deprecated    bool           `json:"deprecated,omitempty"`
+	ReadOnly      bool           `json:"readOnly,omitempty"`
+	WriteOnly     bool           `json:"writeOnly,omitempty"`
+	Required      bool           `json:"required,omitempty"` // required property of the parent object
+	Nullable      bool           `json:"nullable,omitempty"` // pointers, sql.Null* types and nullable tags
+	UniqueItems   bool           `json:"uniqueItems,omitempty"`
+	AllOf         []Property     `json:"allOf,omitempty"` // embedded structs (see Options.EmbedAsAllOf) and all of unions
+	XML           *XML           `json:"xml,omitempty"`   // xml object, see Options.NameTag
+	OneOf         []Property     `json:"oneOf,omitempty"`
+	AnyOf         []Property     `json:"anyOf,omitempty"`
+	Discriminator *Discriminator `json:"discriminator,omitempty"` // discriminator of the union, see Union
 }
 
 func (p *Property) WithName(s string) *Property {
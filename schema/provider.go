@@ -0,0 +1,169 @@
+package schema
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Provider is implemented by types which describe their own schema,
+// the builder uses the returned property instead of reflecting on the type
+type Provider interface {
+	OpenAPISchema() *Property
+}
+
+var (
+	providerType      = reflect.TypeOf((*Provider)(nil)).Elem()
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// implements returns the value as the interface, methods with pointer receivers
+// are called on a copy when the value is not addressable. Zero value is used
+// when there is no valid value.
+func implements(t reflect.Type, v reflect.Value, iface reflect.Type) (interface{}, bool) {
+	if t.Kind() == reflect.Ptr || t.Kind() == reflect.Interface {
+		return nil, false
+	}
+	if !v.IsValid() || !v.CanInterface() {
+		v = reflect.New(t).Elem()
+	}
+	if t.Implements(iface) {
+		return v.Interface(), true
+	}
+	if reflect.PtrTo(t).Implements(iface) {
+		if v.CanAddr() {
+			return v.Addr().Interface(), true
+		}
+		p := reflect.New(t)
+		p.Elem().Set(v)
+		return p.Interface(), true
+	}
+	return nil, false
+}
+
+// inspectProvided returns the property of types which implement Provider,
+// json.Marshaler or encoding.TextMarshaler. Marshalers are only called on
+// values of the object, same as encoding/json, panics are returned as errors.
+func (b *Builder) inspectProvided(t reflect.Type, v reflect.Value) (prop *Property, ok bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			prop, ok, err = nil, true, fmt.Errorf("%s panicked: %v", t, r)
+		}
+	}()
+	hasValue := v.IsValid() && v.CanInterface()
+
+	if i, ok := implements(t, v, providerType); ok {
+		prop := i.(Provider).OpenAPISchema()
+		if prop == nil {
+			return nil, true, nil
+		}
+		_prop := *prop
+		return &_prop, true, nil
+	}
+
+	if i, ok := implements(t, v, jsonMarshalerType); ok {
+		// without a value the JSON is unknown
+		if !hasValue {
+			return &Property{
+				Type: PropType_ANY,
+			}, true, nil
+		}
+		prop, err := b.inspectMarshaledJson(i.(json.Marshaler))
+		return prop, true, err
+	}
+
+	if i, ok := implements(t, v, textMarshalerType); ok {
+		prop := &Property{
+			Type: PropType_STRING,
+		}
+		if hasValue {
+			text, err := i.(encoding.TextMarshaler).MarshalText()
+			if err != nil {
+				return nil, true, err
+			}
+			prop.Value = string(text)
+		}
+		return prop, true, nil
+	}
+
+	return nil, false, nil
+}
+
+// inspectMarshaledJson describes the value by the JSON produced by MarshalJSON
+func (b *Builder) inspectMarshaledJson(m json.Marshaler) (*Property, error) {
+	data, err := m.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var decoded interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&decoded); err != nil {
+		return nil, err
+	}
+	return b.jsonProperty(decoded), nil
+}
+
+// jsonProperty returns the property of a decoded JSON value, objects are
+// documented with their properties and integral numbers as integers
+func (b *Builder) jsonProperty(decoded interface{}) *Property {
+	switch value := decoded.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		props := make([]Property, 0, len(keys))
+		for _, key := range keys {
+			props = append(props, *b.jsonProperty(value[key]).WithName(key))
+		}
+		return &Property{
+			Type:       PropType_OBJECT,
+			Properties: props,
+		}
+	case []interface{}:
+		props := make([]Property, 0)
+		for i := 0; i < len(value) && i < b.maxItems(); i++ {
+			props = append(props, *b.jsonProperty(value[i]))
+		}
+		prop := &Property{
+			Type:       PropType_ARRAY,
+			Properties: props,
+		}
+		if len(props) > 1 {
+			prop.Elem = mergeProperties(props)
+		}
+		return prop
+	case json.Number:
+		if i, err := value.Int64(); err == nil {
+			return &Property{
+				Type:  PropType_INTEGER,
+				Value: i,
+			}
+		}
+		f, _ := value.Float64()
+		return &Property{
+			Type:  PropType_NUMBER,
+			Value: f,
+		}
+	case string:
+		return &Property{
+			Type:  PropType_STRING,
+			Value: value,
+		}
+	case bool:
+		return &Property{
+			Type:  PropType_BOOLEAN,
+			Value: value,
+		}
+	default:
+		return &Property{
+			Type: PropType_ANY,
+		}
+	}
+}
@@ -1,18 +1,20 @@
 package schema
 
 import (
+	"encoding/hex"
 	"fmt"
 	"reflect"
-	"strings"
+	"sort"
 )
 
 func NewBuilderDefault() Builder {
-	return Builder{
-		Options: &Options{
-			ExploreNilStruct: true,
-			PreferJsonTag:    true,
-		},
-	}
+	return NewBuilder(&Options{
+		ExploreNilStruct:  true,
+		PreferJsonTag:     true,
+		TagPrefix:         DefaultTagPrefix,
+		IgnoreUnexported:  true,
+		RespectJsonIgnore: true,
+	})
 }
 
 func NewBuilder(opts *Options) Builder {
@@ -24,10 +26,48 @@ func NewBuilder(opts *Options) Builder {
 type Options struct {
 	ExploreNilStruct bool
 	PreferJsonTag    bool
+	// TagPrefix struct tag key of the schema metadata, default: qd
+	TagPrefix string
+	// IgnoreUnexported skip unexported struct fields
+	IgnoreUnexported bool
+	// RespectJsonIgnore skip struct fields tagged with json:"-"
+	RespectJsonIgnore bool
+	// RespectOmitempty fields tagged with omitempty are optional
+	RespectOmitempty bool
+	// OptionalPointers pointer and interface fields are optional
+	OptionalPointers bool
+	// ConstraintTags struct tags which hold validation rules, default: validate, binding
+	ConstraintTags []string
+	// EmbedAsAllOf document embedded named structs as allOf references instead of promoting their fields
+	EmbedAsAllOf bool
+	// TypeMappings custom type mappings, see DefaultTypeMappings for the built-in ones
+	TypeMappings map[reflect.Type]TypeMapping
+	// NameTag struct tag which governs property names, ignored and omitempty fields, default: json
+	NameTag string
+	// NamingStrategy converts the names of the fields without a name tag, field names are used as is when nil
+	NamingStrategy NamingStrategy
+	// KindPolicies custom kind policies, see DefaultKindPolicies for the built-in ones
+	KindPolicies map[reflect.Kind]KindPolicy
+	// Unions implementations of interface types, see WithUnion
+	Unions map[reflect.Type]Union
+	// MaxItems number of slice elements which are inspected, default: DefaultMaxItems
+	MaxItems int
+	// Cache type level metadata which is shared by the builders, no caching when nil
+	Cache *Cache
+	// RefTypes named struct types which are described as references (see Property.Ref)
+	RefTypes map[reflect.Type]bool
 }
 
 type Builder struct {
 	Options *Options
+
+	visiting  map[reflect.Type]int  // named struct types which are being inspected
+	recursive map[reflect.Type]bool // named struct types which refer to themselves
+	pointers  map[uintptr]bool      // pointers which are being inspected
+	path      []string              // field path of the value which is being inspected
+	skipped   []string              // field paths of the skipped values
+	cycles    int                   // number of times a type was found inside itself
+	typeOnly  bool                  // inspecting a type without a value, see GetSchemaType
 }
 
 func (b *Builder) GetSchema(obj interface{}) (*Property, error) {
@@ -35,90 +75,262 @@ func (b *Builder) GetSchema(obj interface{}) (*Property, error) {
 		return nil, nil
 	}
 	t := reflect.TypeOf(obj)
-	v := reflect.ValueOf(obj)
+	b.begin(t)
+	return b.inspect(t, reflect.ValueOf(obj))
+}
 
-	return b.inspect(t, v)
+// begin prepares the builder to inspect the root type
+func (b *Builder) begin(t reflect.Type) {
+	if b.visiting == nil {
+		b.visiting = make(map[reflect.Type]int)
+		b.recursive = make(map[reflect.Type]bool)
+		b.pointers = make(map[uintptr]bool)
+	}
+	root := t
+	for root.Kind() == reflect.Ptr {
+		root = root.Elem()
+	}
+	b.skipped = nil
+	b.path = []string{root.String()}
+	if root.Name() != "" {
+		b.path = []string{root.Name()}
+	}
+}
+
+// exploreNil reports whether structs without a value are explored
+func (b *Builder) exploreNil() bool {
+	return b.Options.ExploreNilStruct || b.typeOnly
 }
 
 func (b *Builder) inspect(t reflect.Type, v reflect.Value) (*Property, error) {
+	// zero values give the same schema every time, same as types without a value
+	if b.Options.Cache != nil && (!v.IsValid() || v.IsZero()) {
+		return b.inspectCached(t, v)
+	}
+	return b.inspectType(t, v)
+}
+
+func (b *Builder) inspectType(t reflect.Type, v reflect.Value) (*Property, error) {
+	if m, ok := b.typeMapping(t); ok {
+		return b.mappedProperty(m, v), nil
+	}
+	if prop, ok, err := b.inspectProvided(t, v); ok {
+		if err != nil {
+			return nil, b.fieldError(err)
+		}
+		return prop, nil
+	}
 	switch t.Kind() {
 	case reflect.Interface:
-		if v.Interface() == nil {
+		if u, ok := b.Options.Unions[t]; ok {
+			return b.inspectUnion(v, u)
+		}
+		if !v.IsValid() || v.IsNil() {
 			return nil, nil
 		}
 		return b.inspect(v.Elem().Type(), v.Elem())
 	case reflect.Ptr:
-		if !v.IsValid() {
+		if !v.IsValid() || v.IsNil() {
+			return b.inspect(t.Elem(), reflect.Value{})
+		}
+		// a pointer which is already being inspected can only be described by its type
+		if b.pointers[v.Pointer()] {
 			return b.inspect(t.Elem(), reflect.Value{})
 		}
+		b.pointers[v.Pointer()] = true
+		defer delete(b.pointers, v.Pointer())
 		return b.inspect(t.Elem(), v.Elem())
 	case reflect.String:
 		return &Property{
 			Type:  PropType_STRING,
-			Value: b.valueString(v),
+			Value: b.value(v),
 		}, nil
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
 		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 		return &Property{
 			Type:  PropType_INTEGER,
-			Value: b.valueString(v),
+			Value: b.value(v),
 		}, nil
 	case reflect.Bool:
 		return &Property{
 			Type:  PropType_BOOLEAN,
-			Value: b.valueString(v),
+			Value: b.value(v),
 		}, nil
 	case reflect.Struct:
-		if !v.IsValid() && !b.Options.ExploreNilStruct {
+		if t.Name() != "" && b.visiting[t] > 0 {
+			b.recursive[t] = true
+			b.cycles++
+			// without a value the type would be explored forever, refer to the outer one
+			if !v.IsValid() {
+				return &Property{
+					Type: PropType_OBJECT,
+					Ref:  TypeRef(t),
+				}, nil
+			}
+		}
+		// referred types are always explored, the reference needs a schema to point at
+		referred := b.Options.RefTypes[t] || b.unionMember(t)
+		if !v.IsValid() && !b.exploreNil() && !referred {
 			return &Property{
-				Type:  PropType_STRUCT,
-				Value: "",
+				Type: PropType_STRUCT,
 			}, nil
 		}
-		props, err := b.inspectStruct(t, v)
+		if t.Name() != "" {
+			b.visiting[t]++
+			defer func() { b.visiting[t]-- }()
+		}
+		props, allOf, err := b.inspectStruct(t, v)
 		if err != nil {
 			return nil, err
 		}
-		return &Property{
+		prop := &Property{
 			Type:       PropType_OBJECT,
 			Properties: props,
-		}, nil
+			AllOf:      allOf,
+			XML:        b.xmlStruct(t),
+		}
+		if b.recursive[t] || referred {
+			prop.Ref = TypeRef(t)
+		}
+		return prop, nil
 	case reflect.Slice:
-		props := make([]Property, 0)
-		if v.Len() == 0 {
-			prop, err := b.inspect(t.Elem(), reflect.Value{})
-			if err != nil {
-				return nil, err
-			}
-			props = append(props, *prop)
-		} else {
-			for i := 0; i < v.Len(); i++ {
-				prop, err := b.inspect(t.Elem(), v.Index(i))
-				if err != nil {
-					return nil, err
-				}
-				props = append(props, *prop)
-			}
+		return b.inspectNamed(t, v, PropType_ARRAY, b.inspectList)
+	case reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return b.inspectByteArray(t, v), nil
 		}
-		return &Property{
-			Type:       PropType_ARRAY,
-			Properties: props,
-		}, nil
+		prop, err := b.inspectNamed(t, v, PropType_ARRAY, b.inspectList)
+		if err != nil {
+			return nil, err
+		}
+		n := float64(t.Len())
+		return prop.WithConstraint(Constraint{Type: ConType_BETWEEN, Min: n, Max: n}), nil
 	case reflect.Float64, reflect.Float32:
 		return &Property{
 			Type:  PropType_NUMBER,
-			Value: b.valueString(v),
+			Value: b.value(v),
 		}, nil
 	case reflect.Map:
-		panic("not implemented")
+		return b.inspectNamed(t, v, PropType_MAP, b.inspectMap)
 	default:
-		panic("unknown type")
+		return b.inspectByPolicy(t, v)
 	}
 }
 
-func (b *Builder) inspectStruct(t reflect.Type, v reflect.Value) ([]Property, error) {
+// inspectNamed inspects a slice, array or map type with inspect. Same as
+// structs, named types which contain themselves are referred by Ref, without
+// a value the inner occurrence is not explored.
+func (b *Builder) inspectNamed(t reflect.Type, v reflect.Value, propType PropType, inspect func(reflect.Type, reflect.Value) (*Property, error)) (*Property, error) {
+	if t.Name() == "" {
+		return inspect(t, v)
+	}
+	if b.visiting[t] > 0 {
+		b.recursive[t] = true
+		b.cycles++
+		if !v.IsValid() {
+			return &Property{
+				Type: propType,
+				Ref:  TypeRef(t),
+			}, nil
+		}
+	}
+	b.visiting[t]++
+	defer func() { b.visiting[t]-- }()
+	prop, err := inspect(t, v)
+	if err != nil {
+		return nil, err
+	}
+	if b.recursive[t] {
+		prop.Ref = TypeRef(t)
+	}
+	return prop, nil
+}
+
+// inspectList returns the property of a slice or an array, every element gives an item
+func (b *Builder) inspectList(t reflect.Type, v reflect.Value) (*Property, error) {
+	b.pushPath("[]")
+	defer b.popPath()
 	props := make([]Property, 0)
-	for i := 0; i < t.NumField(); i++ {
+	if !v.IsValid() || v.Len() == 0 {
+		prop, err := b.inspect(t.Elem(), reflect.Value{})
+		if err != nil {
+			return nil, err
+		}
+		if prop != nil {
+			props = append(props, *prop)
+		}
+	} else {
+		for i := 0; i < v.Len() && i < b.maxItems(); i++ {
+			prop, err := b.inspect(t.Elem(), v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			if prop == nil {
+				continue
+			}
+			props = append(props, *prop)
+		}
+	}
+	prop := &Property{
+		Type:       PropType_ARRAY,
+		Properties: props,
+	}
+	if len(props) > 1 {
+		prop.Elem = mergeProperties(props)
+	}
+	// empty slices are encoded as [], only nil slices are null
+	if v.IsValid() && v.Kind() == reflect.Slice && !v.IsNil() && v.Len() == 0 {
+		prop.Value = []interface{}{}
+	}
+	return prop, nil
+}
+
+// inspectByteArray byte arrays are documented as hex strings of a fixed length
+func (b *Builder) inspectByteArray(t reflect.Type, v reflect.Value) *Property {
+	n := float64(2 * t.Len())
+	prop := &Property{
+		Type: PropType_STRING,
+		Constraints: []Constraint{
+			{Type: ConType_BETWEEN, Min: n, Max: n},
+			{Type: ConType_PATTERN, Value: "^[0-9a-fA-F]*$"},
+		},
+	}
+	if v.IsValid() {
+		// values of unexported fields can be read, but not copied
+		bytes := make([]byte, t.Len())
+		for i := range bytes {
+			bytes[i] = byte(v.Index(i).Uint())
+		}
+		prop.Value = hex.EncodeToString(bytes)
+	}
+	return prop
+}
+
+// structField property of a struct field with the details to resolve name conflicts
+type structField struct {
+	prop   Property
+	depth  int
+	tagged bool
+}
+
+// inspectStruct returns the properties of the struct fields. Fields of embedded
+// structs are promoted following encoding/json rules, or returned as allOf
+// references when Options.EmbedAsAllOf is set.
+func (b *Builder) inspectStruct(t reflect.Type, v reflect.Value) ([]Property, []Property, error) {
+	fields, allOf, err := b.collectFields(t, v, 0, map[reflect.Type]bool{t: true})
+	if err != nil {
+		return nil, nil, err
+	}
+	return dominantFields(fields), allOf, nil
+}
+
+func (b *Builder) collectFields(t reflect.Type, v reflect.Value, depth int, embedded map[reflect.Type]bool) ([]structField, []Property, error) {
+	fields := make([]structField, 0)
+	var allOf []Property
+	for i, info := range b.structFields(t) {
+		if info.ignored {
+			continue
+		}
 		_field := t.Field(i)
 		var _value reflect.Value
 		if v.IsValid() {
@@ -126,30 +338,270 @@ func (b *Builder) inspectStruct(t reflect.Type, v reflect.Value) ([]Property, er
 		} else {
 			_value = reflect.Value{}
 		}
+
+		if et := info.embedded; et != nil {
+			// embedded pointers may be nil
+			if _field.Type.Kind() == reflect.Ptr {
+				if _value.IsValid() && !_value.IsNil() {
+					_value = _value.Elem()
+				} else {
+					_value = reflect.Value{}
+				}
+			}
+			if embedded[et] {
+				continue
+			}
+			if b.Options.EmbedAsAllOf {
+				props, _allOf, err := b.inspectStruct(et, _value)
+				if err != nil {
+					return nil, nil, err
+				}
+				allOf = append(allOf, Property{
+					Type:       PropType_OBJECT,
+					Properties: props,
+					AllOf:      _allOf,
+					Ref:        TypeRef(et),
+				})
+				continue
+			}
+			embedded[et] = true
+			promoted, _, err := b.collectFields(et, _value, depth+1, embedded)
+			delete(embedded, et)
+			if err != nil {
+				return nil, nil, err
+			}
+			fields = append(fields, promoted...)
+			continue
+		}
+
+		// unexported embedded fields are only promoted when they are structs
+		if _field.Anonymous && _field.PkgPath != "" {
+			continue
+		}
+
+		b.pushPath(info.name)
 		prop, err := b.inspect(_field.Type, _value)
+		b.popPath()
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		if prop == nil {
 			continue
 		}
 		prop = prop.
-			WithName(b.structFieldName(_field))
-		props = append(props, *prop)
+			WithName(info.name)
+		prop.Required = info.required
+		// pointer fields may be null, the nullable tag can override it. Files
+		// are left out of multipart bodies instead
+		prop.Nullable = prop.Nullable || (_field.Type.Kind() == reflect.Ptr && prop.Format != "binary")
+		b.applyConstraints(prop, info.constraints)
+		b.applyTag(prop, info.tags)
+		b.applyXml(prop, _field)
+		fields = append(fields, structField{
+			prop:   *prop,
+			depth:  depth,
+			tagged: info.tagged,
+		})
+	}
+	return fields, allOf, nil
+}
+
+// embeddedStruct returns the struct type of an embedded field which is promoted,
+// embedded fields with a json name are documented as regular fields
+func (b *Builder) embeddedStruct(sf reflect.StructField) (reflect.Type, bool) {
+	if !sf.Anonymous || b.tagName(sf) != "" {
+		return nil, false
+	}
+	t := sf.Type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t, t.Kind() == reflect.Struct
+}
+
+// dominantFields resolves fields with the same name as encoding/json does. The
+// shallowest field wins, then the tagged one, fields which are still ambiguous
+// are dropped. Properties keep the order of the dominant fields.
+func dominantFields(fields []structField) []Property {
+	byName := make(map[string][]int)
+	for i, f := range fields {
+		byName[f.prop.Name] = append(byName[f.prop.Name], i)
+	}
+
+	dominant := make(map[int]bool)
+	for _, indexes := range byName {
+		if i, ok := dominantField(fields, indexes); ok {
+			dominant[i] = true
+		}
 	}
-	return props, nil
+
+	props := make([]Property, 0)
+	for i, f := range fields {
+		if dominant[i] {
+			props = append(props, f.prop)
+		}
+	}
+	return props
+}
+
+func dominantField(fields []structField, indexes []int) (int, bool) {
+	if len(indexes) == 1 {
+		return indexes[0], true
+	}
+	minDepth := fields[indexes[0]].depth
+	for _, i := range indexes {
+		if fields[i].depth < minDepth {
+			minDepth = fields[i].depth
+		}
+	}
+	var shallow []int
+	for _, i := range indexes {
+		if fields[i].depth == minDepth {
+			shallow = append(shallow, i)
+		}
+	}
+	if len(shallow) == 1 {
+		return shallow[0], true
+	}
+	var tagged []int
+	for _, i := range shallow {
+		if fields[i].tagged {
+			tagged = append(tagged, i)
+		}
+	}
+	if len(tagged) == 1 {
+		return tagged[0], true
+	}
+	return 0, false
+}
+
+// inspectMap builds a PropType_MAP property. Map entries are kept as named
+// properties (sorted by key) and the value type is exposed through Elem.
+func (b *Builder) inspectMap(t reflect.Type, v reflect.Value) (*Property, error) {
+	prop := &Property{
+		Type:       PropType_MAP,
+		Properties: make([]Property, 0),
+	}
+	b.pushPath("[]")
+	defer b.popPath()
+
+	// empty maps are encoded as {}, only nil maps are null
+	if v.IsValid() && !v.IsNil() && v.Len() == 0 {
+		prop.Value = map[string]interface{}{}
+	}
+
+	// text marshaled keys are strings too
+	if _, ok := implements(t.Key(), reflect.Value{}, textMarshalerType); !ok && t.Key().Kind() != reflect.String {
+		key, err := b.inspect(t.Key(), reflect.Value{})
+		if err != nil {
+			return nil, err
+		}
+		prop.Key = key
+	}
+
+	if v.IsValid() && v.Len() > 0 {
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return b.valueString(keys[i]) < b.valueString(keys[j])
+		})
+		for _, k := range keys {
+			entry, err := b.inspect(t.Elem(), v.MapIndex(k))
+			if err != nil {
+				return nil, err
+			}
+			if entry == nil {
+				continue
+			}
+			prop.Properties = append(prop.Properties, *entry.WithName(b.valueString(k)))
+		}
+	}
+
+	// interface values may hold different types, leave Elem empty for those
+	if len(prop.Properties) > 0 && t.Elem().Kind() != reflect.Interface {
+		elem := prop.Properties[0]
+		elem.Name = ""
+		prop.Elem = &elem
+		return prop, nil
+	}
+
+	elem, err := b.inspect(t.Elem(), reflect.Value{})
+	if err != nil {
+		return nil, err
+	}
+	prop.Elem = elem
+	return prop, nil
+}
+
+// ignoreField reports whether the struct field is left out from the schema
+func (b *Builder) ignoreField(sf reflect.StructField) bool {
+	if b.Options.IgnoreUnexported && sf.PkgPath != "" && !sf.Anonymous {
+		return true
+	}
+	if b.xmlNameField(sf) {
+		return true
+	}
+	return b.Options.RespectJsonIgnore && sf.Tag.Get(b.nameTag()) == "-"
+}
+
+// requiredField reports whether the struct field is required. Required fields
+// are only computed when at least one of the optional field rules is enabled.
+func (b *Builder) requiredField(sf reflect.StructField) bool {
+	if !b.Options.RespectOmitempty && !b.Options.OptionalPointers {
+		return false
+	}
+	if b.Options.RespectOmitempty {
+		for _, opt := range b.tagOptions(sf) {
+			if opt == "omitempty" {
+				return false
+			}
+		}
+	}
+	if b.Options.OptionalPointers {
+		if k := sf.Type.Kind(); k == reflect.Ptr || k == reflect.Interface {
+			return false
+		}
+	}
+	return true
 }
 
 func (b *Builder) structFieldName(sf reflect.StructField) string {
 	if b.Options.PreferJsonTag {
-		jsonTag := strings.Split(sf.Tag.Get("json"), ",")[0]
-		if jsonTag != "" {
-			return jsonTag
+		if name := b.tagName(sf); name != "" {
+			return name
 		}
+		if naming, ok := tagNamings[b.nameTag()]; ok {
+			return naming(sf.Name)
+		}
+	}
+	if b.Options.NamingStrategy != nil {
+		return b.Options.NamingStrategy(sf.Name)
 	}
 	return sf.Name
 }
 
+// value returns the typed example of a primitive value, nil for invalid values
+func (b *Builder) value(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint()
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.Bool:
+		return v.Bool()
+	}
+	if v.CanInterface() {
+		return v.Interface()
+	}
+	return b.valueString(v)
+}
+
 func (b *Builder) valueString(v reflect.Value) string {
 	if !v.IsValid() {
 		return "nil"
@@ -1,8 +1,18 @@
 package schema
 
 import (
+	"database/sql"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
+	"unsafe"
 )
 
 type UserAccount1 struct {
@@ -74,7 +84,7 @@ func Test_TypeBoolean(t *testing.T) {
 
 	want := &Property{
 		Type:  PropType_BOOLEAN,
-		Value: "true",
+		Value: true,
 	}
 
 	if err != nil {
@@ -93,7 +103,7 @@ func Test_TypeInteger(t *testing.T) {
 
 	want := &Property{
 		Type:  PropType_INTEGER,
-		Value: "10",
+		Value: int64(10),
 	}
 
 	if err != nil {
@@ -128,7 +138,7 @@ func Test_TypeStruct_1(t *testing.T) {
 			{
 				Type:  PropType_INTEGER,
 				Name:  "age",
-				Value: "22",
+				Value: int64(22),
 			},
 			{
 				Type: PropType_OBJECT,
@@ -142,7 +152,7 @@ func Test_TypeStruct_1(t *testing.T) {
 					{
 						Type:  PropType_BOOLEAN,
 						Name:  "valid",
-						Value: "false",
+						Value: false,
 					},
 				},
 			},
@@ -178,21 +188,20 @@ func Test_TypeStruct_2(t *testing.T) {
 			{
 				Type:  PropType_INTEGER,
 				Name:  "age",
-				Value: "22",
+				Value: int64(22),
 			},
 			{
-				Type: PropType_OBJECT, // nil object
-				Name: "log",
+				Type:     PropType_OBJECT, // nil object
+				Name:     "log",
+				Nullable: true,
 				Properties: []Property{
 					{
-						Type:  PropType_STRING,
-						Name:  "date",
-						Value: "nil",
+						Type: PropType_STRING,
+						Name: "date",
 					},
 					{
-						Type:  PropType_BOOLEAN,
-						Name:  "valid",
-						Value: "nil",
+						Type: PropType_BOOLEAN,
+						Name: "valid",
 					},
 				},
 			},
@@ -231,7 +240,7 @@ func Test_TypeStruct_3(t *testing.T) {
 			{
 				Type:  PropType_INTEGER,
 				Name:  "age",
-				Value: "22",
+				Value: int64(22),
 			},
 			{
 				Type: PropType_OBJECT, // nil object
@@ -245,7 +254,7 @@ func Test_TypeStruct_3(t *testing.T) {
 					{
 						Type:  PropType_BOOLEAN,
 						Name:  "valid",
-						Value: "false",
+						Value: false,
 					},
 				},
 			},
@@ -281,7 +290,7 @@ func Test_TypeStruct_4(t *testing.T) {
 			{
 				Type:  PropType_INTEGER,
 				Name:  "age",
-				Value: "22",
+				Value: int64(22),
 			},
 		},
 	}
@@ -305,15 +314,388 @@ func Test_TypeSlice(t *testing.T) {
 		Properties: []Property{
 			{
 				Type:  PropType_INTEGER,
-				Value: "1",
+				Value: int64(1),
+			},
+			{
+				Type:  PropType_INTEGER,
+				Value: int64(2),
+			},
+			{
+				Type:  PropType_INTEGER,
+				Value: int64(3),
+			},
+		},
+		Elem: &Property{
+			Type:  PropType_INTEGER,
+			Value: int64(1),
+		},
+	}
+
+	if err != nil {
+		t.Errorf("error while generating schema, %e", err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("not match \ngot =%v\nwant=%v", got, want)
+	}
+}
+
+func Test_TypeMap(t *testing.T) {
+	sb := NewBuilderDefault()
+
+	got, err := sb.GetSchema(map[string]int{"b": 2, "a": 1})
+
+	want := &Property{
+		Type: PropType_MAP,
+		Properties: []Property{
+			{
+				Type:  PropType_INTEGER,
+				Name:  "a",
+				Value: int64(1),
+			},
+			{
+				Type:  PropType_INTEGER,
+				Name:  "b",
+				Value: int64(2),
+			},
+		},
+		Elem: &Property{
+			Type:  PropType_INTEGER,
+			Value: int64(1),
+		},
+	}
+
+	if err != nil {
+		t.Errorf("error while generating schema, %e", err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("not match \ngot =%v\nwant=%v", got, want)
+	}
+}
+
+func Test_TypeMap_IntKey(t *testing.T) {
+	sb := NewBuilderDefault()
+
+	got, err := sb.GetSchema(map[int]LogEntry{})
+
+	want := &Property{
+		Type:       PropType_MAP,
+		Properties: []Property{},
+		Value:      map[string]interface{}{},
+		Key: &Property{
+			Type: PropType_INTEGER,
+		},
+		Elem: &Property{
+			Type: PropType_OBJECT,
+			Properties: []Property{
+				{
+					Type: PropType_STRING,
+					Name: "date",
+				},
+				{
+					Type: PropType_BOOLEAN,
+					Name: "valid",
+				},
+			},
+		},
+	}
+
+	if err != nil {
+		t.Errorf("error while generating schema, %e", err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("not match \ngot =%v\nwant=%v", got, want)
+	}
+}
+
+type Basket struct {
+	Items   []string          `json:"items"`
+	Missing []string          `json:"missing"`
+	Labels  map[string]string `json:"labels"`
+	Notes   map[string]string `json:"notes"`
+}
+
+func Test_EmptyCollections(t *testing.T) {
+	sb := NewBuilderDefault()
+
+	got, err := sb.GetSchema(Basket{Items: []string{}, Labels: map[string]string{}})
+	if err != nil {
+		t.Fatalf("error while generating schema, %v", err)
+	}
+
+	want := []interface{}{[]interface{}{}, nil, map[string]interface{}{}, nil}
+	for i, p := range got.Properties {
+		if !reflect.DeepEqual(p.Value, want[i]) {
+			t.Errorf("%s: got value %#v, want %#v", p.Name, p.Value, want[i])
+		}
+	}
+}
+
+type Category struct {
+	Name     string     `json:"name"`
+	Children []Category `json:"children"`
+}
+
+type Node struct {
+	Value int   `json:"value"`
+	Next  *Node `json:"next"`
+}
+
+func Test_TypeRecursive(t *testing.T) {
+	sb := NewBuilderDefault()
+
+	got, err := sb.GetSchema(Category{Name: "root"})
+
+	ref := "github.com/pickme-lk/quick-doc/schema.Category"
+	want := &Property{
+		Type: PropType_OBJECT,
+		Ref:  ref,
+		Properties: []Property{
+			{
+				Type:  PropType_STRING,
+				Name:  "name",
+				Value: "root",
+			},
+			{
+				Type: PropType_ARRAY,
+				Name: "children",
+				Properties: []Property{
+					{
+						Type: PropType_OBJECT,
+						Ref:  ref,
+					},
+				},
 			},
+		},
+	}
+
+	if err != nil {
+		t.Errorf("error while generating schema, %e", err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("not match \ngot =%v\nwant=%v", got, want)
+	}
+}
+
+type Tree map[string]Tree
+
+type List []List
+
+type Checksum struct {
+	Name string
+	hash [2]byte
+}
+
+func Test_TypeByteArrayUnexported(t *testing.T) {
+	sb := NewBuilder(&Options{})
+	got, err := sb.GetSchema(Checksum{Name: "a", hash: [2]byte{0xab, 0x01}})
+	if err != nil {
+		t.Fatalf("error while generating schema, %v", err)
+	}
+	if hash := got.Properties[1]; hash.Name != "hash" || hash.Value != "ab01" {
+		t.Errorf("unexported byte array should be a hex string, got=%+v", hash)
+	}
+}
+
+type Session struct {
+	started time.Time
+	token   sql.NullString
+}
+
+func Test_TypeMappingUnexported(t *testing.T) {
+	sb := NewBuilder(&Options{})
+	got, err := sb.GetSchema(Session{started: time.Now(), token: sql.NullString{String: "a", Valid: true}})
+	if err != nil {
+		t.Fatalf("error while generating schema, %v", err)
+	}
+	for _, p := range got.Properties {
+		if p.Type != PropType_STRING || p.Value != nil {
+			t.Errorf("unexported %s should be a string without an example, got=%+v", p.Name, p)
+		}
+	}
+}
+
+func Test_TypeRecursiveNamed(t *testing.T) {
+	sb := NewBuilderDefault()
+
+	got, err := sb.GetSchema(Tree{"a": Tree{}})
+	if err != nil {
+		t.Fatalf("error while generating schema, %v", err)
+	}
+	tree := &Property{Type: PropType_MAP, Ref: "github.com/pickme-lk/quick-doc/schema.Tree"}
+	if got.Ref != tree.Ref || len(got.Properties) != 1 {
+		t.Fatalf("tree should be referred, got=%+v", got)
+	}
+	if !reflect.DeepEqual(got.Properties[0].Elem, tree) {
+		t.Errorf("inner tree should refer the outer one, got=%+v", got.Properties[0].Elem)
+	}
+
+	got, err = sb.GetSchemaType(reflect.TypeOf(List{}))
+	if err != nil {
+		t.Fatalf("error while generating schema, %v", err)
+	}
+	list := Property{Type: PropType_ARRAY, Ref: "github.com/pickme-lk/quick-doc/schema.List"}
+	if got.Ref != list.Ref || len(got.Properties) != 1 || !reflect.DeepEqual(got.Properties[0], list) {
+		t.Errorf("list should refer itself, got=%+v", got)
+	}
+}
+
+func Test_TypeRecursivePointerCycle(t *testing.T) {
+	sb := NewBuilderDefault()
+
+	node := &Node{Value: 1}
+	node.Next = node
+	got, err := sb.GetSchema(node)
+
+	ref := "github.com/pickme-lk/quick-doc/schema.Node"
+	want := &Property{
+		Type: PropType_OBJECT,
+		Ref:  ref,
+		Properties: []Property{
 			{
 				Type:  PropType_INTEGER,
-				Value: "2",
+				Name:  "value",
+				Value: int64(1),
+			},
+			{
+				Type:     PropType_OBJECT,
+				Name:     "next",
+				Ref:      ref,
+				Nullable: true,
+			},
+		},
+	}
+
+	if err != nil {
+		t.Errorf("error while generating schema, %e", err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("not match \ngot =%v\nwant=%v", got, want)
+	}
+}
+
+type Account struct {
+	Email  string `json:"email" qd:"desc='login email, must be unique',example=user@example.com,format=email"`
+	Status int    `json:"status" qd:"enum=1|2|3,readOnly"`
+	Legacy string `json:"legacy" doc:"deprecated"`
+}
+
+func Test_StructTag(t *testing.T) {
+	sb := NewBuilder(&Options{
+		PreferJsonTag: true,
+		TagPrefix:     "doc",
+	})
+
+	got, err := sb.GetSchema(Account{Status: 1})
+
+	want := &Property{
+		Type: PropType_OBJECT,
+		Properties: []Property{
+			{
+				Type:  PropType_STRING,
+				Name:  "email",
+				Value: "",
 			},
 			{
 				Type:  PropType_INTEGER,
-				Value: "3",
+				Name:  "status",
+				Value: int64(1),
+			},
+			{
+				Type:       PropType_STRING,
+				Name:       "legacy",
+				Value:      "",
+				Deprecated: true,
+			},
+		},
+	}
+
+	if err != nil {
+		t.Errorf("error while generating schema, %e", err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("not match \ngot =%v\nwant=%v", got, want)
+	}
+
+	sb = NewBuilderDefault()
+	got, err = sb.GetSchema(Account{Status: 1})
+
+	want = &Property{
+		Type: PropType_OBJECT,
+		Properties: []Property{
+			{
+				Type:        PropType_STRING,
+				Name:        "email",
+				Value:       "user@example.com",
+				Description: "login email, must be unique",
+				Format:      "email",
+			},
+			{
+				Type:     PropType_INTEGER,
+				Name:     "status",
+				Value:    int64(1),
+				Enum:     []interface{}{int64(1), int64(2), int64(3)},
+				ReadOnly: true,
+			},
+			{
+				Type:  PropType_STRING,
+				Name:  "legacy",
+				Value: "",
+			},
+		},
+	}
+
+	if err != nil {
+		t.Errorf("error while generating schema, %e", err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("not match \ngot =%v\nwant=%v", got, want)
+	}
+}
+
+type Profile struct {
+	Name     string    `json:"name"`
+	Nickname string    `json:"nickname,omitempty"`
+	Log      *LogEntry `json:"log"`
+	Secret   string    `json:"-"`
+	internal string
+}
+
+func Test_StructFieldRules(t *testing.T) {
+	sb := NewBuilder(&Options{
+		PreferJsonTag:     true,
+		IgnoreUnexported:  true,
+		RespectJsonIgnore: true,
+		RespectOmitempty:  true,
+		OptionalPointers:  true,
+	})
+
+	got, err := sb.GetSchema(Profile{Name: "Test User"})
+
+	want := &Property{
+		Type: PropType_OBJECT,
+		Properties: []Property{
+			{
+				Type:     PropType_STRING,
+				Name:     "name",
+				Value:    "Test User",
+				Required: true,
+			},
+			{
+				Type:  PropType_STRING,
+				Name:  "nickname",
+				Value: "",
+			},
+			{
+				Type:     PropType_STRUCT,
+				Name:     "log",
+				Nullable: true,
 			},
 		},
 	}
@@ -326,3 +708,941 @@ func Test_TypeSlice(t *testing.T) {
 		t.Errorf("not match \ngot =%v\nwant=%v", got, want)
 	}
 }
+
+type SignUp struct {
+	Username string   `json:"username" validate:"required,min=3,max=20,alphanum"`
+	Email    string   `json:"email,omitempty" binding:"required,email"`
+	Role     int      `json:"role" validate:"oneof=1 2"`
+	Tags     []string `json:"tags" validate:"max=5,dive,min=2"`
+}
+
+func Test_Constraints(t *testing.T) {
+	sb := NewBuilderDefault()
+
+	got, err := sb.GetSchema(SignUp{})
+
+	want := &Property{
+		Type: PropType_OBJECT,
+		Properties: []Property{
+			{
+				Type:     PropType_STRING,
+				Name:     "username",
+				Value:    "",
+				Required: true,
+				Constraints: []Constraint{
+					{Type: ConType_REQUIRED},
+					{Type: ConType_MIN, Min: 3},
+					{Type: ConType_MAX, Max: 20},
+					{Type: ConType_PATTERN, Value: "^[a-zA-Z0-9]+$"},
+				},
+			},
+			{
+				Type:     PropType_STRING,
+				Name:     "email",
+				Value:    "",
+				Required: true,
+				Constraints: []Constraint{
+					{Type: ConType_REQUIRED},
+					{Type: ConType_FORMAT, Value: "email"},
+				},
+			},
+			{
+				Type:  PropType_INTEGER,
+				Name:  "role",
+				Value: int64(0),
+				Enum:  []interface{}{int64(1), int64(2)},
+				Constraints: []Constraint{
+					{Type: ConType_ONE_OF, Values: []string{"1", "2"}},
+				},
+			},
+			{
+				Type: PropType_ARRAY,
+				Name: "tags",
+				Properties: []Property{
+					{
+						Type: PropType_STRING,
+					},
+				},
+				Constraints: []Constraint{
+					{Type: ConType_MAX, Max: 5},
+				},
+			},
+		},
+	}
+
+	if err != nil {
+		t.Errorf("error while generating schema, %e", err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("not match \ngot =%v\nwant=%v", got, want)
+	}
+}
+
+type Base struct {
+	ID      int    `json:"id"`
+	Created string `json:"created"`
+}
+
+type Audit struct {
+	Created string `json:"created"`
+	By      string `json:"by"`
+}
+
+type Named struct {
+	Name string
+}
+
+type Admin struct {
+	Base
+	*Audit
+	Named `json:"named"`
+	Level int    `json:"level"`
+	By    string `json:"by"`
+}
+
+func Test_EmbeddedStruct(t *testing.T) {
+	sb := NewBuilderDefault()
+
+	got, err := sb.GetSchema(Admin{Base: Base{ID: 1}, Level: 2})
+
+	want := &Property{
+		Type: PropType_OBJECT,
+		Properties: []Property{
+			{
+				Type:  PropType_INTEGER,
+				Name:  "id",
+				Value: int64(1),
+			},
+			{
+				Type: PropType_OBJECT,
+				Name: "named",
+				Properties: []Property{
+					{
+						Type:  PropType_STRING,
+						Name:  "Name",
+						Value: "",
+					},
+				},
+			},
+			{
+				Type:  PropType_INTEGER,
+				Name:  "level",
+				Value: int64(2),
+			},
+			{
+				Type:  PropType_STRING,
+				Name:  "by",
+				Value: "",
+			},
+		},
+	}
+
+	if err != nil {
+		t.Errorf("error while generating schema, %e", err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("not match \ngot =%v\nwant=%v", got, want)
+	}
+}
+
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+type Order struct {
+	Created time.Time       `json:"created"`
+	Payload []byte          `json:"payload"`
+	Note    sql.NullString  `json:"note"`
+	Total   Money           `json:"total"`
+	Raw     json.RawMessage `json:"raw"`
+}
+
+func Test_TypeMapping(t *testing.T) {
+	sb := NewBuilderDefault()
+	sb.Options.WithTypeMapping(reflect.TypeOf(Money{}), TypeMapping{
+		Type:   PropType_STRING,
+		Format: "money",
+		Example: func(v reflect.Value) interface{} {
+			m := v.Interface().(Money)
+			return fmt.Sprintf("%d %s", m.Amount, m.Currency)
+		},
+	})
+
+	got, err := sb.GetSchema(Order{
+		Created: time.Date(2022, 1, 21, 10, 0, 0, 0, time.UTC),
+		Payload: []byte("hi"),
+		Note:    sql.NullString{String: "gift", Valid: true},
+		Total:   Money{Amount: 100, Currency: "LKR"},
+	})
+
+	want := &Property{
+		Type: PropType_OBJECT,
+		Properties: []Property{
+			{
+				Type:   PropType_STRING,
+				Name:   "created",
+				Value:  "2022-01-21T10:00:00Z",
+				Format: "date-time",
+			},
+			{
+				Type:   PropType_STRING,
+				Name:   "payload",
+				Value:  "aGk=",
+				Format: "byte",
+			},
+			{
+				Type:     PropType_STRING,
+				Name:     "note",
+				Value:    "gift",
+				Nullable: true,
+			},
+			{
+				Type:   PropType_STRING,
+				Name:   "total",
+				Value:  "100 LKR",
+				Format: "money",
+			},
+			{
+				Type: PropType_ANY,
+				Name: "raw",
+			},
+		},
+	}
+
+	if err != nil {
+		t.Errorf("error while generating schema, %e", err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("not match \ngot =%v\nwant=%v", got, want)
+	}
+}
+
+type Peer struct {
+	Addr    net.IP `json:"addr"`
+	Gateway net.IP `json:"gateway"`
+}
+
+func Test_TypeMappingIP(t *testing.T) {
+	sb := NewBuilderDefault()
+
+	got, err := sb.GetSchema(Peer{Addr: net.ParseIP("10.0.0.1")})
+	if err != nil {
+		t.Fatalf("error while generating schema, %v", err)
+	}
+
+	if v := got.Properties[0].Value; v != "10.0.0.1" {
+		t.Errorf("addr example not match, got=%v", v)
+	}
+	if v := got.Properties[1].Value; v != nil {
+		t.Errorf("empty ip should have no example, got=%v", v)
+	}
+}
+
+type Price struct {
+	cents int64
+}
+
+func (p Price) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf(`{"amount":%d.%02d}`, p.cents/100, p.cents%100)), nil
+}
+
+type OrderID struct {
+	id int
+}
+
+func (o *OrderID) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("ORD-%d", o.id)), nil
+}
+
+type Color int
+
+func (c Color) OpenAPISchema() *Property {
+	return &Property{
+		Type: PropType_STRING,
+		Enum: []interface{}{"red", "green"},
+	}
+}
+
+type Invoice struct {
+	ID    OrderID `json:"id"`
+	Price Price   `json:"price"`
+	Color Color   `json:"color"`
+}
+
+func Test_Provided(t *testing.T) {
+	sb := NewBuilderDefault()
+
+	got, err := sb.GetSchema(Invoice{ID: OrderID{id: 7}, Price: Price{cents: 1050}})
+
+	want := &Property{
+		Type: PropType_OBJECT,
+		Properties: []Property{
+			{
+				Type:  PropType_STRING,
+				Name:  "id",
+				Value: "ORD-7",
+			},
+			{
+				Type: PropType_OBJECT,
+				Name: "price",
+				Properties: []Property{
+					{
+						Type:  PropType_NUMBER,
+						Name:  "amount",
+						Value: 10.5,
+					},
+				},
+			},
+			{
+				Type: PropType_STRING,
+				Name: "color",
+				Enum: []interface{}{"red", "green"},
+			},
+		},
+	}
+
+	if err != nil {
+		t.Errorf("error while generating schema, %e", err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("not match \ngot =%v\nwant=%v", got, want)
+	}
+}
+
+type Receipt struct {
+	id int
+}
+
+func (r Receipt) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf(`{"id":%d,"paid":true,"lines":[1,2],"note":null}`, r.id)), nil
+}
+
+func Test_ProvidedJsonObject(t *testing.T) {
+	sb := NewBuilderDefault()
+
+	got, err := sb.GetSchema(Receipt{id: 12})
+
+	integer := func(i int64) Property {
+		return Property{Type: PropType_INTEGER, Value: i}
+	}
+	want := &Property{
+		Type: PropType_OBJECT,
+		Properties: []Property{
+			{Type: PropType_INTEGER, Name: "id", Value: int64(12)},
+			{
+				Type:       PropType_ARRAY,
+				Name:       "lines",
+				Properties: []Property{integer(1), integer(2)},
+				Elem:       &Property{Type: PropType_INTEGER, Value: int64(1)},
+			},
+			{Type: PropType_ANY, Name: "note"},
+			{Type: PropType_BOOLEAN, Name: "paid", Value: true},
+		},
+	}
+
+	if err != nil {
+		t.Errorf("error while generating schema, %e", err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("not match \ngot =%+v\nwant=%+v", got, want)
+	}
+}
+
+type amount struct {
+	Cents int64
+}
+
+type Wallet struct {
+	v *amount
+}
+
+func (w Wallet) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf(`{"cents":%d}`, w.v.Cents)), nil
+}
+
+type Bill struct {
+	Price *Wallet `json:"price"`
+}
+
+func Test_ProvidedWithoutValue(t *testing.T) {
+	sb := NewBuilderDefault()
+
+	got, err := sb.GetSchema(Bill{})
+	if err != nil {
+		t.Fatalf("error while generating schema, %v", err)
+	}
+	if price := got.Properties[0]; price.Type != PropType_ANY || price.Value != nil {
+		t.Errorf("nil marshaler should be any without an example, got=%+v", price)
+	}
+
+	got, err = sb.GetSchemaType(reflect.TypeOf(Bill{}))
+	if err != nil {
+		t.Fatalf("error while generating schema, %v", err)
+	}
+	if price := got.Properties[0]; price.Type != PropType_ANY || price.Value != nil {
+		t.Errorf("marshaler of a type only schema should be any without an example, got=%+v", price)
+	}
+
+	// the marshaler panics on the value
+	_, err = sb.GetSchema(Bill{Price: &Wallet{}})
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Path != "Bill.price" {
+		t.Errorf("marshaler panic should be a field error, got=%v", err)
+	}
+}
+
+type Broken struct {
+	Payload struct {
+		Items []struct {
+			Events chan int `json:"events"`
+		} `json:"items"`
+	} `json:"payload"`
+}
+
+func Test_UnsupportedType(t *testing.T) {
+	sb := NewBuilderDefault()
+	sb.Options.KindPolicies = map[reflect.Kind]KindPolicy{
+		reflect.Chan: KindPolicy_ERROR,
+	}
+
+	_, err := sb.GetSchema(Broken{})
+
+	var typeErr *UnsupportedTypeError
+	if !errors.As(err, &typeErr) {
+		t.Fatalf("unsupported type error expected, got=%v", err)
+	}
+	if typeErr.Path != "Broken.payload.items[].events" {
+		t.Errorf("path not match, got=%s", typeErr.Path)
+	}
+}
+
+type Job struct {
+	Name     string         `json:"name" qd:"example=sync"`
+	Done     chan bool      `json:"done"`
+	Callback func() error   `json:"callback"`
+	Ratio    complex128     `json:"ratio"`
+	Handle   uintptr        `json:"handle"`
+	Ptr      unsafe.Pointer `json:"ptr"`
+}
+
+func Test_KindPolicies(t *testing.T) {
+	sb := NewBuilderDefault()
+
+	got, err := sb.GetSchema(Job{Ratio: complex(1, 2), Handle: 8})
+	if err != nil {
+		t.Fatalf("error while generating schema, %v", err)
+	}
+
+	want := &Property{
+		Type: PropType_OBJECT,
+		Properties: []Property{
+			{Type: PropType_STRING, Name: "name", Value: "sync"},
+			{Type: PropType_STRING, Name: "ratio", Value: "(1+2i)"},
+			{Type: PropType_INTEGER, Name: "handle", Value: uint64(8)},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("not match \ngot =%v\nwant=%v", got, want)
+	}
+
+	skipped := []string{"Job.done (chan bool)", "Job.callback (func() error)", "Job.ptr (unsafe.Pointer)"}
+	if !reflect.DeepEqual(sb.Skipped(), skipped) {
+		t.Errorf("skipped not match \ngot =%v\nwant=%v", sb.Skipped(), skipped)
+	}
+
+	sb.Options.KindPolicies = map[reflect.Kind]KindPolicy{reflect.Uintptr: KindPolicy_STRING}
+	got, err = sb.GetSchema(Job{Handle: 8})
+	if err != nil {
+		t.Fatalf("error while generating schema, %v", err)
+	}
+	if handle := got.Properties[2]; handle.Type != PropType_STRING || handle.Value != "8" {
+		t.Errorf("handle should follow the kind policy, got=%+v", handle)
+	}
+}
+
+type Naming struct {
+	UserID       string `json:"uid"`
+	HTTPServer   string
+	FirstName    string `json:",omitempty"`
+	Address2Line string
+}
+
+func Test_NamingStrategy(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy NamingStrategy
+		want     []string
+	}{
+		{"none", nil, []string{"uid", "HTTPServer", "FirstName", "Address2Line"}},
+		{"camel", CamelCase, []string{"uid", "httpServer", "firstName", "address2Line"}},
+		{"pascal", PascalCase, []string{"uid", "HttpServer", "FirstName", "Address2Line"}},
+		{"snake", SnakeCase, []string{"uid", "http_server", "first_name", "address2_line"}},
+		{"kebab", KebabCase, []string{"uid", "http-server", "first-name", "address2-line"}},
+		{"custom", strings.ToUpper, []string{"uid", "HTTPSERVER", "FIRSTNAME", "ADDRESS2LINE"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sb := NewBuilderDefault()
+			sb.Options.NamingStrategy = tt.strategy
+
+			got, err := sb.GetSchema(Naming{})
+			if err != nil {
+				t.Fatalf("error while generating schema, %v", err)
+			}
+			var names []string
+			for _, p := range got.Properties {
+				names = append(names, p.Name)
+			}
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("names not match \ngot =%v\nwant=%v", names, tt.want)
+			}
+		})
+	}
+}
+
+func Test_SplitWords(t *testing.T) {
+	tests := map[string][]string{
+		"UserID":       {"User", "ID"},
+		"ID":           {"ID"},
+		"HTTPServerID": {"HTTP", "Server", "ID"},
+		"userId":       {"user", "Id"},
+		"snake_case":   {"snake", "case"},
+		"Address2Line": {"Address2", "Line"},
+	}
+	for name, want := range tests {
+		if got := splitWords(name); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s not match, got=%v want=%v", name, got, want)
+		}
+	}
+}
+
+type PurchaseOrder struct {
+	XMLName xml.Name `json:"-" form:"-" xml:"urn:orders order"`
+	ID      string   `json:"id" form:"order_id" xml:"id,attr"`
+	Items   []string `json:"items,omitempty" form:"item" xml:"items>item"`
+	Note    string   `json:"note" form:"-" xml:"note,omitempty"`
+}
+
+func Test_NameTag(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want *Property
+	}{
+		{"", &Property{
+			Type: PropType_OBJECT,
+			Properties: []Property{
+				{Type: PropType_STRING, Name: "id", Value: "", Required: true},
+				{Type: PropType_ARRAY, Name: "items", Properties: []Property{{Type: PropType_STRING}}},
+				{Type: PropType_STRING, Name: "note", Value: "", Required: true},
+			},
+		}},
+		{"form", &Property{
+			Type: PropType_OBJECT,
+			Properties: []Property{
+				{Type: PropType_STRING, Name: "order_id", Value: "", Required: true},
+				{Type: PropType_ARRAY, Name: "item", Properties: []Property{{Type: PropType_STRING}}, Required: true},
+			},
+		}},
+		{"xml", &Property{
+			Type: PropType_OBJECT,
+			XML:  &XML{Name: "order", Namespace: "urn:orders"},
+			Properties: []Property{
+				{Type: PropType_STRING, Name: "id", Value: "", Required: true, XML: &XML{Attribute: true}},
+				{
+					Type:       PropType_ARRAY,
+					Name:       "items",
+					Properties: []Property{{Type: PropType_STRING, XML: &XML{Name: "item"}}},
+					Required:   true,
+					XML:        &XML{Wrapped: true},
+				},
+				{Type: PropType_STRING, Name: "note", Value: ""},
+			},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			sb := NewBuilder(&Options{
+				PreferJsonTag:     true,
+				IgnoreUnexported:  true,
+				RespectJsonIgnore: true,
+				RespectOmitempty:  true,
+				NameTag:           tt.tag,
+			})
+
+			got, err := sb.GetSchema(PurchaseOrder{})
+			if err != nil {
+				t.Fatalf("error while generating schema, %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("not match \ngot =%+v\nwant=%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+type Payment interface {
+	Amount() int
+}
+
+type CardPayment struct {
+	Type string `json:"type"`
+	Card string `json:"card"`
+}
+
+func (p CardPayment) Amount() int { return 0 }
+
+type WalletPayment struct {
+	Type   string `json:"type"`
+	Wallet string `json:"wallet"`
+}
+
+func (p WalletPayment) Amount() int { return 0 }
+
+type Checkout struct {
+	Payment Payment `json:"payment"`
+}
+
+func Test_Union(t *testing.T) {
+	sb := NewBuilderDefault()
+	sb.Options.ExploreNilStruct = false
+	sb.Options.WithUnion(reflect.TypeOf((*Payment)(nil)).Elem(), Union{
+		Types: []reflect.Type{reflect.TypeOf(CardPayment{}), reflect.TypeOf(WalletPayment{})},
+		Discriminator: NewDiscriminator("type", map[string]interface{}{
+			"card":   CardPayment{},
+			"wallet": &WalletPayment{},
+		}),
+	})
+
+	got, err := sb.GetSchema(Checkout{Payment: CardPayment{Type: "card", Card: "4111"}})
+	if err != nil {
+		t.Fatalf("error while generating schema, %v", err)
+	}
+
+	want := &Property{
+		Type: PropType_OBJECT,
+		Properties: []Property{
+			{
+				Type: PropType_ANY,
+				Name: "payment",
+				OneOf: []Property{
+					{
+						Type: PropType_OBJECT,
+						Ref:  TypeRef(reflect.TypeOf(CardPayment{})),
+						Properties: []Property{
+							{Type: PropType_STRING, Name: "type", Value: "card"},
+							{Type: PropType_STRING, Name: "card", Value: "4111"},
+						},
+					},
+					{
+						Type: PropType_OBJECT,
+						Ref:  TypeRef(reflect.TypeOf(WalletPayment{})),
+						Properties: []Property{
+							{Type: PropType_STRING, Name: "type"},
+							{Type: PropType_STRING, Name: "wallet"},
+						},
+					},
+				},
+				Discriminator: &Discriminator{
+					PropertyName: "type",
+					Mapping: map[string]string{
+						"card":   "github.com/pickme-lk/quick-doc/schema.CardPayment",
+						"wallet": "github.com/pickme-lk/quick-doc/schema.WalletPayment",
+					},
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("not match \ngot =%+v\nwant=%+v", got, want)
+	}
+}
+
+type Contact struct {
+	Email   *string        `json:"email"`
+	Phone   string         `json:"phone" qd:"nullable"`
+	Address *string        `json:"address" qd:"nullable=false"`
+	Mobile  sql.NullString `json:"mobile"`
+}
+
+func Test_Nullable(t *testing.T) {
+	sb := NewBuilderDefault()
+
+	got, err := sb.GetSchema(&Contact{})
+	if err != nil {
+		t.Fatalf("error while generating schema, %v", err)
+	}
+
+	want := &Property{
+		Type: PropType_OBJECT,
+		Properties: []Property{
+			{Type: PropType_STRING, Name: "email", Nullable: true},
+			{Type: PropType_STRING, Name: "phone", Value: "", Nullable: true},
+			{Type: PropType_STRING, Name: "address"},
+			{Type: PropType_STRING, Name: "mobile", Nullable: true},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("not match \ngot =%+v\nwant=%+v", got, want)
+	}
+}
+
+type Matrix struct {
+	Point [3]int   `json:"point"`
+	Hash  [4]byte  `json:"hash"`
+	Tags  []string `json:"tags" validate:"unique" qd:"minItems=1,maxItems=5"`
+}
+
+func Test_TypeArray(t *testing.T) {
+	sb := NewBuilderDefault()
+
+	got, err := sb.GetSchema(Matrix{Point: [3]int{1, 2, 3}, Hash: [4]byte{0xde, 0xad, 0xbe, 0xef}})
+	if err != nil {
+		t.Fatalf("error while generating schema, %v", err)
+	}
+
+	want := &Property{
+		Type: PropType_OBJECT,
+		Properties: []Property{
+			{
+				Type: PropType_ARRAY,
+				Name: "point",
+				Properties: []Property{
+					{Type: PropType_INTEGER, Value: int64(1)},
+					{Type: PropType_INTEGER, Value: int64(2)},
+					{Type: PropType_INTEGER, Value: int64(3)},
+				},
+				Elem:        &Property{Type: PropType_INTEGER, Value: int64(1)},
+				Constraints: []Constraint{{Type: ConType_BETWEEN, Min: 3, Max: 3}},
+			},
+			{
+				Type:  PropType_STRING,
+				Name:  "hash",
+				Value: "deadbeef",
+				Constraints: []Constraint{
+					{Type: ConType_BETWEEN, Min: 8, Max: 8},
+					{Type: ConType_PATTERN, Value: "^[0-9a-fA-F]*$"},
+				},
+			},
+			{
+				Type:        PropType_ARRAY,
+				Name:        "tags",
+				Properties:  []Property{{Type: PropType_STRING}},
+				UniqueItems: true,
+				Constraints: []Constraint{
+					{Type: ConType_UNIQUE},
+					{Type: ConType_MIN, Min: 1},
+					{Type: ConType_MAX, Max: 5},
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("not match \ngot =%+v\nwant=%+v", got, want)
+	}
+}
+
+type Event struct {
+	Kind  string `json:"kind"`
+	Order int    `json:"order,omitempty"`
+	Label string `json:"label,omitempty"`
+}
+
+func Test_TypeSliceMerge(t *testing.T) {
+	sb := NewBuilderDefault()
+	sb.Options.RespectOmitempty = true
+
+	got, err := sb.GetSchema([]interface{}{
+		map[string]interface{}{"kind": "created"},
+		Event{Kind: "paid", Order: 7},
+		"note",
+		Event{Kind: "shipped", Label: "fast"},
+	})
+	if err != nil {
+		t.Fatalf("error while generating schema, %v", err)
+	}
+
+	if len(got.Properties) != 4 {
+		t.Errorf("every element should be kept for the example, got=%d", len(got.Properties))
+	}
+	want := &Property{
+		Type: PropType_ANY,
+		OneOf: []Property{
+			{
+				Type:       PropType_MAP,
+				Properties: []Property{{Type: PropType_STRING, Name: "kind", Value: "created"}},
+			},
+			{
+				Type: PropType_OBJECT,
+				Properties: []Property{
+					{Type: PropType_STRING, Name: "kind", Value: "paid", Required: true},
+					{Type: PropType_INTEGER, Name: "order", Value: int64(7)},
+					{Type: PropType_STRING, Name: "label", Value: ""},
+				},
+			},
+			{Type: PropType_STRING, Value: "note"},
+		},
+	}
+	if !reflect.DeepEqual(got.Elem, want) {
+		t.Errorf("not match \ngot =%+v\nwant=%+v", got.Elem, want)
+	}
+}
+
+func Test_TypeSliceMaxItems(t *testing.T) {
+	sb := NewBuilderDefault()
+	sb.Options.MaxItems = 2
+
+	got, err := sb.GetSchema([]int{1, 2, 3, 4})
+	if err != nil {
+		t.Fatalf("error while generating schema, %v", err)
+	}
+	if len(got.Properties) != 2 {
+		t.Errorf("only 2 elements should be inspected, got=%d", len(got.Properties))
+	}
+}
+
+type Shop struct {
+	Name      string         `json:"name" validate:"required,max=40" qd:"desc='shop name'"`
+	Owner     *Profile       `json:"owner"`
+	Customers []UserAccount2 `json:"customers"`
+	Orders    []Order        `json:"orders"`
+	Tree      Category       `json:"tree"`
+	Skip      chan int       `json:"skip"`
+}
+
+func Test_Cache(t *testing.T) {
+	values := []interface{}{
+		Shop{},
+		Shop{Name: "corner", Customers: []UserAccount2{{Name: "a"}, {}}},
+		Category{Name: "root", Children: []Category{{Name: "leaf"}}},
+		&Node{Value: 1},
+		Matrix{},
+	}
+	cache := NewCache()
+	for i := 0; i < 2; i++ {
+		for _, value := range values {
+			sb := NewBuilderDefault()
+			want, err := sb.GetSchema(value)
+			if err != nil {
+				t.Fatalf("error while generating schema, %v", err)
+			}
+			cached := NewBuilderDefault()
+			cached.Options.Cache = cache
+			got, err := cached.GetSchema(value)
+			if err != nil {
+				t.Fatalf("error while generating schema, %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("cached schema of %T not match \ngot =%+v\nwant=%+v", value, got, want)
+			}
+			if !reflect.DeepEqual(cached.Skipped(), sb.Skipped()) {
+				t.Errorf("skipped of %T not match, got=%v want=%v", value, cached.Skipped(), sb.Skipped())
+			}
+		}
+	}
+}
+
+type Ticket struct {
+	ID     string    `json:"id" validate:"uuid"`
+	Title  string    `json:"title" qd:"example=printer"`
+	Status string    `json:"status" validate:"oneof=open closed"`
+	Seats  int       `json:"seats" validate:"gt=1"`
+	Price  float64   `json:"price"`
+	Paid   bool      `json:"paid"`
+	Opened time.Time `json:"opened"`
+	Tags   []string  `json:"tags"`
+	Owner  *Profile  `json:"owner"`
+	Code   string    `json:"code" validate:"min=10"`
+	Ref    string    `json:"ref" validate:"min=10,alpha"`
+	Short  string    `json:"short" validate:"max=3"`
+}
+
+func Test_SchemaType(t *testing.T) {
+	sb := NewBuilderDefault()
+	got, err := sb.GetSchemaType(reflect.TypeOf(Ticket{}))
+	if err != nil {
+		t.Fatalf("error while generating schema, %v", err)
+	}
+	examples := map[string]interface{}{
+		"id":     "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+		"title":  "printer",
+		"status": "open",
+		"seats":  int64(2),
+		"price":  float64(0),
+		"paid":   false,
+		"opened": "2022-01-21T10:00:00Z",
+		"code":   "stringstri",
+		"ref":    nil,
+		"short":  "str",
+	}
+	for _, p := range got.Properties {
+		if want, ok := examples[p.Name]; ok && !reflect.DeepEqual(p.Value, want) {
+			t.Errorf("example of %s not match, got=%#v want=%#v", p.Name, p.Value, want)
+		}
+	}
+	tags := got.Properties[7]
+	if len(tags.Properties) != 1 || tags.Properties[0].Value != "string" {
+		t.Errorf("array item should have a synthesized example, got=%+v", tags.Properties)
+	}
+	// nil structs are explored when there is no value
+	owner := got.Properties[8]
+	if owner.Type != PropType_OBJECT || len(owner.Properties) == 0 {
+		t.Errorf("owner should be explored, got=%+v", owner)
+	}
+
+	if prop, err := sb.GetSchemaType(nil); prop != nil || err != nil {
+		t.Errorf("nil type should have no schema, got=%v, %v", prop, err)
+	}
+}
+
+type AvatarForm struct {
+	Name   string                  `form:"name"`
+	Avatar *multipart.FileHeader   `form:"avatar"`
+	Photos []*multipart.FileHeader `form:"photos"`
+}
+
+func Test_FileHeader(t *testing.T) {
+	sb := NewBuilderDefault()
+	sb.Options.NameTag = "form"
+	got, err := sb.GetSchemaType(reflect.TypeOf(AvatarForm{}))
+	if err != nil {
+		t.Fatalf("error while generating schema, %v", err)
+	}
+	avatar := got.Properties[1]
+	if avatar.Name != "avatar" || avatar.Type != PropType_STRING || avatar.Format != "binary" || avatar.Value != nil || avatar.Nullable {
+		t.Errorf("avatar should be a binary string without an example, got=%+v", avatar)
+	}
+	photo := got.Properties[2].ItemSchema()
+	if photo == nil || photo.Type != PropType_STRING || photo.Format != "binary" {
+		t.Errorf("photos should be an array of binary strings, got=%+v", photo)
+	}
+}
+
+func benchmarkGetSchema(b *testing.B, cache *Cache) {
+	value := Shop{
+		Name:      "corner",
+		Customers: make([]UserAccount2, 20),
+		Orders:    make([]Order, 20),
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		sb := NewBuilderDefault()
+		sb.Options.Cache = cache
+		if _, err := sb.GetSchema(value); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGetSchema(b *testing.B) {
+	benchmarkGetSchema(b, nil)
+}
+
+func BenchmarkGetSchemaCached(b *testing.B) {
+	benchmarkGetSchema(b, NewCache())
+}
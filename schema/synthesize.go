@@ -0,0 +1,138 @@
+package schema
+
+import (
+	"reflect"
+	"strings"
+)
+
+// examples of string formats which are used when a type has no value
+var formatExamples = map[string]interface{}{
+	"date-time": "2022-01-21T10:00:00Z",
+	"date":      "2022-01-21",
+	"email":     "user@example.com",
+	"uuid":      "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+	"uri":       "https://example.com",
+	"hostname":  "example.com",
+	"ipv4":      "192.168.0.1",
+	"ipv6":      "::1",
+	"byte":      "c3RyaW5n",
+	"binary":    nil, // files have no example
+}
+
+// GetSchemaType returns the schema of the type without an example value.
+// Structs are always explored, properties without an example from the tags
+// get a synthesized one.
+func (b *Builder) GetSchemaType(t reflect.Type) (*Property, error) {
+	if t == nil {
+		return nil, nil
+	}
+	b.typeOnly = true
+	defer func() { b.typeOnly = false }()
+	b.begin(t)
+
+	prop, err := b.inspect(t, reflect.Value{})
+	if err != nil || prop == nil {
+		return prop, err
+	}
+	synthesizeExamples(prop)
+	return prop, nil
+}
+
+// synthesizeExamples sets an example on every primitive property which has none
+func synthesizeExamples(prop *Property) {
+	for _, props := range [][]Property{prop.Properties, prop.AllOf, prop.OneOf, prop.AnyOf} {
+		for i := range props {
+			synthesizeExamples(&props[i])
+		}
+	}
+	if prop.Elem != nil {
+		synthesizeExamples(prop.Elem)
+	}
+	if prop.Value == nil {
+		prop.Value = synthesizedValue(prop)
+	}
+}
+
+// synthesizedValue returns an example which fits the property, the first enum
+// value, a format example, a string of an allowed length or the smallest
+// allowed number. Strings with a pattern have no example
+func synthesizedValue(prop *Property) interface{} {
+	if len(prop.Enum) > 0 {
+		return prop.Enum[0]
+	}
+	switch prop.Type {
+	case PropType_STRING:
+		if hasConstraint(prop, ConType_PATTERN) {
+			return nil
+		}
+		if example, ok := formatExamples[propFormat(prop)]; ok {
+			return example
+		}
+		return stringValue(prop)
+	case PropType_INTEGER:
+		return int64(minValue(prop))
+	case PropType_NUMBER:
+		return minValue(prop)
+	case PropType_BOOLEAN:
+		return false
+	default:
+		return nil
+	}
+}
+
+// stringValue returns "string" repeated or cut to fit the length constraints
+func stringValue(prop *Property) string {
+	n := len("string")
+	if min := int(minValue(prop)); min > n {
+		n = min
+	}
+	for _, c := range prop.Constraints {
+		if c.Type == ConType_MAX || c.Type == ConType_BETWEEN {
+			max := int(c.Max)
+			if c.Exclusive {
+				max--
+			}
+			if max < n {
+				n = max
+			}
+		}
+	}
+	if n < 0 {
+		n = 0
+	}
+	return strings.Repeat("string", n/len("string")+1)[:n]
+}
+
+func hasConstraint(prop *Property, t ConstraintType) bool {
+	for _, c := range prop.Constraints {
+		if c.Type == t {
+			return true
+		}
+	}
+	return false
+}
+
+func minValue(prop *Property) float64 {
+	for _, c := range prop.Constraints {
+		if c.Type == ConType_MIN || c.Type == ConType_BETWEEN {
+			if c.Exclusive {
+				return c.Min + 1
+			}
+			return c.Min
+		}
+	}
+	return 0
+}
+
+// propFormat returns the format of the property or of its format constraint
+func propFormat(prop *Property) string {
+	if prop.Format != "" {
+		return prop.Format
+	}
+	for _, c := range prop.Constraints {
+		if c.Type == ConType_FORMAT {
+			return c.Value
+		}
+	}
+	return ""
+}
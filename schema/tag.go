@@ -0,0 +1,142 @@
+package schema
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const DefaultTagPrefix = "qd"
+
+// Tag keys
+const (
+	tagDesc       = "desc"
+	tagExample    = "example"
+	tagFormat     = "format"
+	tagEnum       = "enum"
+	tagDeprecated = "deprecated"
+	tagReadOnly   = "readOnly"
+	tagWriteOnly  = "writeOnly"
+	tagNullable   = "nullable"
+	tagUnique     = "uniqueItems"
+	tagMinItems   = "minItems"
+	tagMaxItems   = "maxItems"
+)
+
+// tagPrefix returns the struct tag key which holds schema metadata
+func (b *Builder) tagPrefix() string {
+	if b.Options.TagPrefix == "" {
+		return DefaultTagPrefix
+	}
+	return b.Options.TagPrefix
+}
+
+// tagItem key and value of a schema metadata tag item
+type tagItem struct {
+	key   string
+	value string
+}
+
+// fieldTags returns the schema metadata of the struct field.
+// Example: `qd:"desc='user email, used to login',example=user@example.com,format=email"`
+func (b *Builder) fieldTags(sf reflect.StructField) []tagItem {
+	tag, ok := sf.Tag.Lookup(b.tagPrefix())
+	if !ok {
+		return nil
+	}
+	var items []tagItem
+	for _, item := range splitTag(tag) {
+		key, value := item, ""
+		if i := strings.Index(item, "="); i >= 0 {
+			key, value = strings.TrimSpace(item[:i]), unquote(strings.TrimSpace(item[i+1:]))
+		}
+		items = append(items, tagItem{key: key, value: value})
+	}
+	return items
+}
+
+// applyTag sets the schema metadata of the struct field on the property
+func (b *Builder) applyTag(prop *Property, items []tagItem) {
+	for _, item := range items {
+		key, value := item.key, item.value
+		switch key {
+		case tagDesc:
+			prop.Description = value
+		case tagExample:
+			prop.Value = parseValue(prop.Type, value)
+		case tagFormat:
+			prop.Format = value
+		case tagEnum:
+			prop.Enum = make([]interface{}, 0)
+			for _, e := range strings.Split(value, "|") {
+				prop.Enum = append(prop.Enum, parseValue(prop.Type, e))
+			}
+		case tagDeprecated:
+			prop.Deprecated = true
+		case tagReadOnly:
+			prop.ReadOnly = true
+		case tagWriteOnly:
+			prop.WriteOnly = true
+		case tagUnique:
+			prop.UniqueItems = true
+		case tagMinItems:
+			if n, err := strconv.ParseFloat(value, 64); err == nil {
+				prop.WithConstraint(Constraint{Type: ConType_MIN, Min: n})
+			}
+		case tagMaxItems:
+			if n, err := strconv.ParseFloat(value, 64); err == nil {
+				prop.WithConstraint(Constraint{Type: ConType_MAX, Max: n})
+			}
+		case tagNullable:
+			// nullable=false documents a pointer as not nullable
+			prop.Nullable = value != "false"
+		}
+	}
+}
+
+// splitTag splits tag items by commas, commas inside single quotes are kept
+func splitTag(tag string) []string {
+	items := make([]string, 0)
+	quoted := false
+	start := 0
+	for i, r := range tag {
+		switch {
+		case r == '\'':
+			quoted = !quoted
+		case r == ',' && !quoted:
+			items = append(items, strings.TrimSpace(tag[start:i]))
+			start = i + 1
+		}
+	}
+	if last := strings.TrimSpace(tag[start:]); last != "" {
+		items = append(items, last)
+	}
+	return items
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'' {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// parseValue converts the tag value into the type of the property,
+// values which can not be converted are kept as strings
+func parseValue(t PropType, s string) interface{} {
+	switch t {
+	case PropType_INTEGER:
+		if v, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return v
+		}
+	case PropType_NUMBER:
+		if v, err := strconv.ParseFloat(s, 64); err == nil {
+			return v
+		}
+	case PropType_BOOLEAN:
+		if v, err := strconv.ParseBool(s); err == nil {
+			return v
+		}
+	}
+	return s
+}
@@ -0,0 +1,112 @@
+package schema
+
+import (
+	"reflect"
+)
+
+// UnionKind how the schemas of the union members are combined
+type UnionKind string
+
+const (
+	UnionKind_ONE_OF UnionKind = "ONE_OF"
+	UnionKind_ANY_OF UnionKind = "ANY_OF"
+	UnionKind_ALL_OF UnionKind = "ALL_OF"
+)
+
+// Union implementations of an interface type
+type Union struct {
+	Kind          UnionKind // default: ONE_OF
+	Types         []reflect.Type
+	Discriminator *Discriminator
+}
+
+// Discriminator property which tells the union members apart
+type Discriminator struct {
+	PropertyName string
+	Mapping      map[string]string // property value -> type reference, see TypeRef
+}
+
+// NewDiscriminator returns a discriminator which maps the property values to
+// the types of the given values. Ex: NewDiscriminator("type", map[string]interface{}{"card": CardPayment{}})
+func NewDiscriminator(propertyName string, mapping map[string]interface{}) *Discriminator {
+	d := &Discriminator{
+		PropertyName: propertyName,
+	}
+	if len(mapping) > 0 {
+		d.Mapping = make(map[string]string)
+	}
+	for value, obj := range mapping {
+		t := reflect.TypeOf(obj)
+		for t != nil && t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t != nil {
+			d.Mapping[value] = TypeRef(t)
+		}
+	}
+	return d
+}
+
+// UnionProperty returns the property which combines the members by the union kind
+func UnionProperty(kind UnionKind, members []Property, d *Discriminator) *Property {
+	prop := &Property{
+		Type:          PropType_ANY,
+		Discriminator: d,
+	}
+	switch kind {
+	case UnionKind_ANY_OF:
+		prop.AnyOf = members
+	case UnionKind_ALL_OF:
+		prop.Type = PropType_OBJECT
+		prop.AllOf = members
+	default:
+		prop.OneOf = members
+	}
+	return prop
+}
+
+// WithUnion registers the implementations of the interface type. Ex:
+// opts.WithUnion(reflect.TypeOf((*Payment)(nil)).Elem(), Union{Types: ...})
+func (o *Options) WithUnion(t reflect.Type, u Union) *Options {
+	if o.Unions == nil {
+		o.Unions = make(map[reflect.Type]Union)
+	}
+	o.Unions[t] = u
+	return o
+}
+
+// unionMember reports whether the type is an implementation of a registered interface
+func (b *Builder) unionMember(t reflect.Type) bool {
+	for _, u := range b.Options.Unions {
+		for _, ut := range u.Types {
+			for ut.Kind() == reflect.Ptr {
+				ut = ut.Elem()
+			}
+			if ut == t {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// inspectUnion describes an interface value by the schemas of all the
+// registered implementations, the implementation which holds the value gives
+// the example
+func (b *Builder) inspectUnion(v reflect.Value, u Union) (*Property, error) {
+	members := make([]Property, 0, len(u.Types))
+	for _, t := range u.Types {
+		mv := reflect.Value{}
+		if v.IsValid() && !v.IsNil() && v.Elem().Type() == t {
+			mv = v.Elem()
+		}
+		prop, err := b.inspect(t, mv)
+		if err != nil {
+			return nil, err
+		}
+		if prop != nil {
+			members = append(members, *prop)
+		}
+	}
+	return UnionProperty(u.Kind, members, u.Discriminator), nil
+}